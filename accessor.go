@@ -0,0 +1,232 @@
+package mdast
+
+import (
+	"fmt"
+	"slices"
+)
+
+// nodeProperties 记录 mdast 规范中各属性所属的节点类型
+var nodeProperties = map[DataKey][]NodeType{
+	NDK_Depth:         {NodeHeading},
+	NDK_Ordered:       {NodeList},
+	NDK_Start:         {NodeList},
+	NDK_Spread:        {NodeList, NodeListItem},
+	NDK_Checked:       {NodeListItem},
+	NDK_Lang:          {NodeCode},
+	NDK_Meta:          {NodeCode},
+	NDK_URL:           {NodeLink, NodeImage, NodeDefinition},
+	NDK_Title:         {NodeLink, NodeImage, NodeDefinition},
+	NDK_Alt:           {NodeImage, NodeImageReference},
+	NDK_Identifier:    {NodeDefinition, NodeFootnoteDefinition, NodeLinkReference, NodeImageReference, NodeFootnoteReference},
+	NDK_Label:         {NodeDefinition, NodeFootnoteDefinition, NodeLinkReference, NodeImageReference, NodeFootnoteReference},
+	NDK_ReferenceType: {NodeLinkReference, NodeImageReference},
+	NDK_Align:         {NodeTable},
+}
+
+// HasProperty 判断 key 是否为该类型节点在 mdast 规范中的属性
+func (nt NodeType) HasProperty(key DataKey) bool {
+	return slices.Contains(nodeProperties[key], nt)
+}
+
+// set 校验属性归属后写入 Data
+func (n *Node) set(key DataKey, value any) error {
+	if !n.Type.HasProperty(key) {
+		return fmt.Errorf("%s is not a property of %s", key, n.Type)
+	}
+	if n.Data == nil {
+		n.Data = make(DataTable)
+	}
+	n.Data[key] = value
+	return nil
+}
+
+// Depth 返回标题的级别，未设置时返回 0
+func (n *Node) Depth() int {
+	depth, _ := n.Data.GetInt(NDK_Depth)
+	return depth
+}
+
+// SetDepth 设置标题的级别，取值范围为 1~6
+func (n *Node) SetDepth(depth int) error {
+	if depth < 1 || depth > 6 {
+		return fmt.Errorf("invalid heading depth %d, expected 1 to 6", depth)
+	}
+	return n.set(NDK_Depth, depth)
+}
+
+// Ordered 返回列表是否为有序列表，未设置 ordered 但设置了 start 时视为有序列表
+func (n *Node) Ordered() bool {
+	if ordered, ok := n.Data.GetBool(NDK_Ordered); ok {
+		return ordered
+	}
+	_, hasStart := n.Data.GetInt(NDK_Start)
+	return hasStart
+}
+
+// SetOrdered 设置列表是否为有序列表
+func (n *Node) SetOrdered(ordered bool) error {
+	return n.set(NDK_Ordered, ordered)
+}
+
+// Start 返回有序列表的起始序号，未设置时 ok 为 false
+func (n *Node) Start() (start int, ok bool) {
+	return n.Data.GetInt(NDK_Start)
+}
+
+// SetStart 设置有序列表的起始序号，不能为负数
+func (n *Node) SetStart(start int) error {
+	if start < 0 {
+		return fmt.Errorf("invalid list start %d, expected 0 or more", start)
+	}
+	return n.set(NDK_Start, start)
+}
+
+// Spread 返回列表或列表项是否为松散的（各项之间有空行）
+func (n *Node) Spread() bool {
+	spread, _ := n.Data.GetBool(NDK_Spread)
+	return spread
+}
+
+// SetSpread 设置列表或列表项是否为松散的
+func (n *Node) SetSpread(spread bool) error {
+	return n.set(NDK_Spread, spread)
+}
+
+// Checked 返回任务列表项的勾选状态，不是任务列表项时 ok 为 false
+func (n *Node) Checked() (checked bool, ok bool) {
+	return n.Data.GetBool(NDK_Checked)
+}
+
+// SetChecked 将列表项设为任务列表项并设置勾选状态
+func (n *Node) SetChecked(checked bool) error {
+	return n.set(NDK_Checked, checked)
+}
+
+// Lang 返回代码块的语言
+func (n *Node) Lang() string {
+	lang, _ := n.Data.GetString(NDK_Lang)
+	return lang
+}
+
+// SetLang 设置代码块的语言，语言中不能包含空白
+func (n *Node) SetLang(lang string) error {
+	for _, c := range lang {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			return fmt.Errorf("invalid code lang %q, expected no whitespace", lang)
+		}
+	}
+	return n.set(NDK_Lang, lang)
+}
+
+// Meta 返回代码块语言之后的元信息
+func (n *Node) Meta() string {
+	meta, _ := n.Data.GetString(NDK_Meta)
+	return meta
+}
+
+// SetMeta 设置代码块的元信息，元信息不能跨行
+func (n *Node) SetMeta(meta string) error {
+	for _, c := range meta {
+		if c == '\n' || c == '\r' {
+			return fmt.Errorf("invalid code meta %q, expected a single line", meta)
+		}
+	}
+	return n.set(NDK_Meta, meta)
+}
+
+// URL 返回链接、图片或定义的地址
+func (n *Node) URL() string {
+	url, _ := n.Data.GetString(NDK_URL)
+	return url
+}
+
+// SetURL 设置链接、图片或定义的地址
+func (n *Node) SetURL(url string) error {
+	return n.set(NDK_URL, url)
+}
+
+// Title 返回链接、图片或定义的标题
+func (n *Node) Title() string {
+	title, _ := n.Data.GetString(NDK_Title)
+	return title
+}
+
+// SetTitle 设置链接、图片或定义的标题
+func (n *Node) SetTitle(title string) error {
+	return n.set(NDK_Title, title)
+}
+
+// Alt 返回图片的替代文本
+func (n *Node) Alt() string {
+	alt, _ := n.Data.GetString(NDK_Alt)
+	return alt
+}
+
+// SetAlt 设置图片的替代文本
+func (n *Node) SetAlt(alt string) error {
+	return n.set(NDK_Alt, alt)
+}
+
+// Identifier 返回定义或引用规范化后的标识符
+func (n *Node) Identifier() string {
+	id, _ := n.Data.GetString(NDK_Identifier)
+	return id
+}
+
+// Label 返回定义或引用在源文中的标签，未设置时返回标识符
+func (n *Node) Label() string {
+	if label, ok := n.Data.GetString(NDK_Label); ok {
+		return label
+	}
+	return n.Identifier()
+}
+
+// SetLabel 设置定义或引用的标签，同时将标识符设为规范化后的标签
+func (n *Node) SetLabel(label string) error {
+	identifier := normalizeLabel(label)
+	if identifier == "" {
+		return fmt.Errorf("invalid label %q, expected at least one non-whitespace character", label)
+	}
+	if len(label) > 999 {
+		return fmt.Errorf("invalid label %q, expected at most 999 characters", label)
+	}
+	if err := n.set(NDK_Identifier, identifier); err != nil {
+		return err
+	}
+	return n.set(NDK_Label, label)
+}
+
+// ReferenceType 返回引用的类型
+func (n *Node) ReferenceType() ReferenceType {
+	referenceType, _ := n.Data.GetReferenceType(NDK_ReferenceType)
+	return referenceType
+}
+
+// SetReferenceType 设置引用的类型
+func (n *Node) SetReferenceType(referenceType ReferenceType) error {
+	switch referenceType {
+	case ReferenceShortcut, ReferenceCollapsed, ReferenceFull:
+		return n.set(NDK_ReferenceType, referenceType)
+	}
+	return fmt.Errorf("invalid reference type %q, expected one of %q", referenceType,
+		[]ReferenceType{ReferenceShortcut, ReferenceCollapsed, ReferenceFull})
+}
+
+// Align 返回表格各列的对齐方式
+func (n *Node) Align() []AlignType {
+	align, _ := n.Data.GetAlignTypes(NDK_Align)
+	return align
+}
+
+// SetAlign 设置表格各列的对齐方式
+func (n *Node) SetAlign(align []AlignType) error {
+	for _, a := range align {
+		switch a {
+		case AlignNone, AlignLeft, AlignRight, AlignCenter:
+		default:
+			return fmt.Errorf("invalid align %q, expected one of %q", a,
+				[]AlignType{AlignNone, AlignLeft, AlignRight, AlignCenter})
+		}
+	}
+	return n.set(NDK_Align, align)
+}
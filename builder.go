@@ -0,0 +1,232 @@
+package mdast
+
+// 以下构造函数创建带有类型化属性的节点。构造函数直接写入属性，不检查取值，
+// 不符合 mdast 规范的值（如超出 1~6 的标题级别）由 Validate 报告；
+// 需要在设置时得到错误，请用 NewNode 创建节点后调用对应的 Set 方法
+
+// setLabel 写入标签和由它规范化得到的标识符，不检查取值
+func setLabel(n *Node, label string) {
+	n.Data[NDK_Identifier] = normalizeLabel(label)
+	n.Data[NDK_Label] = label
+}
+
+func newParent(nodeType NodeType, children ...PhrasingContent) *Node {
+	n := NewNode(nodeType)
+	for _, child := range children {
+		n.AddPhrasingChild(child)
+	}
+	return n
+}
+
+func newFlowParent(nodeType NodeType, children ...FlowContent) *Node {
+	n := NewNode(nodeType)
+	for _, child := range children {
+		n.AddFlowChild(child)
+	}
+	return n
+}
+
+func newLiteral(nodeType NodeType, value string) *Node {
+	n := NewNode(nodeType)
+	n.Value = value
+	return n
+}
+
+// NewRoot 创建根节点
+func NewRoot(children ...FlowContent) *Node {
+	return newFlowParent(NodeRoot, children...)
+}
+
+// NewParagraph 创建段落
+func NewParagraph(children ...PhrasingContent) *Node {
+	return newParent(NodeParagraph, children...)
+}
+
+// NewHeading 创建 depth 级标题
+func NewHeading(depth int, children ...PhrasingContent) *Node {
+	n := newParent(NodeHeading, children...)
+	n.Data[NDK_Depth] = depth
+	return n
+}
+
+// NewThematicBreak 创建分隔线
+func NewThematicBreak() *Node {
+	return NewNode(NodeThematicBreak)
+}
+
+// NewBlockquote 创建引用块
+func NewBlockquote(children ...FlowContent) *Node {
+	return newFlowParent(NodeBlockquote, children...)
+}
+
+// NewList 创建紧凑列表，有序列表从 1 开始编号
+func NewList(ordered bool, items ...ListContent) *Node {
+	n := NewNode(NodeList)
+	n.Data[NDK_Ordered] = ordered
+	n.Data[NDK_Spread] = false
+	if ordered {
+		n.Data[NDK_Start] = 1
+	}
+	for _, item := range items {
+		n.AddListChild(item)
+	}
+	return n
+}
+
+// NewListItem 创建列表项
+func NewListItem(children ...FlowContent) *Node {
+	n := newFlowParent(NodeListItem, children...)
+	n.Data[NDK_Spread] = false
+	return n
+}
+
+// NewTaskListItem 创建带勾选框的任务列表项
+func NewTaskListItem(checked bool, children ...FlowContent) *Node {
+	n := NewListItem(children...)
+	n.Data[NDK_Checked] = checked
+	return n
+}
+
+// NewHTML 创建 HTML 节点
+func NewHTML(value string) *Node {
+	return newLiteral(NodeHTML, value)
+}
+
+// NewCode 创建代码块，lang 和 meta 可以为空
+func NewCode(lang, meta, value string) *Node {
+	n := newLiteral(NodeCode, value)
+	if lang != "" {
+		n.Data[NDK_Lang] = lang
+	}
+	if meta != "" {
+		n.Data[NDK_Meta] = meta
+	}
+	return n
+}
+
+// NewYaml 创建 YAML front matter
+func NewYaml(value string) *Node {
+	return newLiteral(NodeYaml, value)
+}
+
+// NewDefinition 创建链接定义，标识符由 label 规范化得到
+func NewDefinition(label, url, title string) *Node {
+	n := NewNode(NodeDefinition)
+	setLabel(n, label)
+	n.Data[NDK_URL] = url
+	if title != "" {
+		n.Data[NDK_Title] = title
+	}
+	return n
+}
+
+// NewFootnoteDefinition 创建脚注定义，标识符由 label 规范化得到
+func NewFootnoteDefinition(label string, children ...FlowContent) *Node {
+	n := newFlowParent(NodeFootnoteDefinition, children...)
+	setLabel(n, label)
+	return n
+}
+
+// NewTable 创建表格，align 为各列的对齐方式
+func NewTable(align []AlignType, rows ...TableContent) *Node {
+	n := NewNode(NodeTable)
+	n.Data[NDK_Align] = align
+	for _, row := range rows {
+		n.AddTableChild(row)
+	}
+	return n
+}
+
+// NewTableRow 创建表格行
+func NewTableRow(cells ...TableContent) *Node {
+	n := NewNode(NodeTableRow)
+	for _, cell := range cells {
+		n.AddTableChild(cell)
+	}
+	return n
+}
+
+// NewTableCell 创建单元格
+func NewTableCell(children ...PhrasingContent) *Node {
+	return newParent(NodeTableCell, children...)
+}
+
+// NewText 创建文本节点
+func NewText(value string) *Node {
+	return newLiteral(NodeText, value)
+}
+
+// NewEmphasis 创建强调
+func NewEmphasis(children ...PhrasingContent) *Node {
+	return newParent(NodeEmphasis, children...)
+}
+
+// NewStrong 创建加粗
+func NewStrong(children ...PhrasingContent) *Node {
+	return newParent(NodeStrong, children...)
+}
+
+// NewDelete 创建删除线
+func NewDelete(children ...PhrasingContent) *Node {
+	return newParent(NodeDelete, children...)
+}
+
+// NewInlineCode 创建行内代码
+func NewInlineCode(value string) *Node {
+	return newLiteral(NodeInlineCode, value)
+}
+
+// NewBreak 创建硬换行
+func NewBreak() *Node {
+	return NewNode(NodeBreak)
+}
+
+// NewLink 创建链接，title 可以为空
+func NewLink(url, title string, children ...PhrasingContent) *Node {
+	n := newParent(NodeLink, children...)
+	n.Data[NDK_URL] = url
+	if title != "" {
+		n.Data[NDK_Title] = title
+	}
+	return n
+}
+
+// NewImage 创建图片，title 可以为空
+func NewImage(url, title, alt string) *Node {
+	n := NewNode(NodeImage)
+	n.Data[NDK_URL] = url
+	if title != "" {
+		n.Data[NDK_Title] = title
+	}
+	n.Data[NDK_Alt] = alt
+	return n
+}
+
+// NewLinkReference 创建链接引用，标识符由 label 规范化得到
+func NewLinkReference(label string, referenceType ReferenceType, children ...PhrasingContent) *Node {
+	n := newParent(NodeLinkReference, children...)
+	setLabel(n, label)
+	n.Data[NDK_ReferenceType] = referenceType
+	return n
+}
+
+// NewImageReference 创建图片引用，标识符由 label 规范化得到
+func NewImageReference(label string, referenceType ReferenceType, alt string) *Node {
+	n := NewNode(NodeImageReference)
+	setLabel(n, label)
+	n.Data[NDK_ReferenceType] = referenceType
+	n.Data[NDK_Alt] = alt
+	return n
+}
+
+// NewFootnoteReference 创建脚注引用，标识符由 label 规范化得到
+func NewFootnoteReference(label string) *Node {
+	n := NewNode(NodeFootnoteReference)
+	setLabel(n, label)
+	return n
+}
+
+// NewFootnote 创建行内脚注
+func NewFootnote(children ...PhrasingContent) *Node {
+	return newParent(NodeFootnote, children...)
+}
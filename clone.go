@@ -0,0 +1,197 @@
+package mdast
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Clone 深拷贝以 n 为根的子树，包括 Data、Position 和全部子节点，拷贝的子节点指向新的父节点，
+// 根节点的父节点为 nil
+func (n *Node) Clone() *Node {
+	c := &Node{
+		Type:             n.Type,
+		Value:            n.Value,
+		FlowChildren:     cloneChildren(n.FlowChildren),
+		PhrasingChildren: cloneChildren(n.PhrasingChildren),
+		ListChildren:     cloneChildren(n.ListChildren),
+		TableChildren:    cloneChildren(n.TableChildren),
+	}
+	if n.Data != nil {
+		c.Data = make(DataTable, len(n.Data))
+		for k, v := range n.Data {
+			c.Data[k] = cloneValue(v)
+		}
+	}
+	if n.Position != nil {
+		pos := *n.Position
+		if pos.Indent != nil {
+			pos.Indent = append([]int{}, pos.Indent...)
+		}
+		c.Position = &pos
+	}
+	for _, child := range c.Children() {
+		child.parent = c
+	}
+	return c
+}
+
+func cloneChildren[T any](children []T) []T {
+	if children == nil {
+		return nil
+	}
+	result := make([]T, len(children))
+	for i, child := range children {
+		result[i] = any(any(child).(*Node).Clone()).(T)
+	}
+	return result
+}
+
+// cloneValue 深拷贝 Data 中的值，切片、map 和节点会被复制，其余值按值拷贝
+func cloneValue(v any) any {
+	if n, ok := v.(*Node); ok {
+		// 类型为 *Node 的 nil 原样返回
+		if n == nil {
+			return v
+		}
+		return n.Clone()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			setCloned(c.Index(i), rv.Index(i))
+		}
+		return c.Interface()
+	case reflect.Map:
+		if rv.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			setCloned(elem, iter.Value())
+			c.SetMapIndex(iter.Key(), elem)
+		}
+		return c.Interface()
+	}
+	return v
+}
+
+func setCloned(dst, src reflect.Value) {
+	if !src.CanInterface() || (src.Kind() == reflect.Interface && src.IsNil()) {
+		dst.Set(src)
+		return
+	}
+	dst.Set(reflect.ValueOf(cloneValue(src.Interface())).Convert(dst.Type()))
+}
+
+// EqualOptions 控制 Equal 比较哪些内容
+type EqualOptions struct {
+	// IgnorePosition 为 true 时不比较 Position
+	IgnorePosition bool
+	// IgnoreParent 为 true 时不检查父节点指针，否则要求两棵树中子节点的父节点指针同样正确或同样缺失
+	IgnoreParent bool
+}
+
+// Equal 比较两棵树的结构、Value 和 Data，相同时返回 true；
+// 不同时返回 false 和第一处差异，差异以 Validate 相同格式的节点路径开头，如 "root > paragraph[0]: value "a" != "b""。
+// Data 中的整数按数值比较，int 与 int64 视为相同
+func Equal(a, b *Node, opts EqualOptions) (bool, string) {
+	if a == nil || b == nil {
+		if a == b {
+			return true, ""
+		}
+		return false, fmt.Sprintf("node %v != %v", a, b)
+	}
+	diff := equalNode(a, b, string(a.Type), opts)
+	return diff == "", diff
+}
+
+func equalNode(a, b *Node, path string, opts EqualOptions) string {
+	if a.Type != b.Type {
+		return fmt.Sprintf("%s: type %s != %s", path, a.Type, b.Type)
+	}
+	if a.Value != b.Value {
+		return fmt.Sprintf("%s: value %q != %q", path, a.Value, b.Value)
+	}
+	if diff := equalData(a.Data, b.Data); diff != "" {
+		return path + ": " + diff
+	}
+	if !opts.IgnorePosition && !reflect.DeepEqual(a.Position, b.Position) {
+		return fmt.Sprintf("%s: position %v != %v", path, a.Position, b.Position)
+	}
+
+	slots := []struct {
+		name string
+		a, b int
+	}{
+		{"FlowChildren", len(a.FlowChildren), len(b.FlowChildren)},
+		{"PhrasingChildren", len(a.PhrasingChildren), len(b.PhrasingChildren)},
+		{"ListChildren", len(a.ListChildren), len(b.ListChildren)},
+		{"TableChildren", len(a.TableChildren), len(b.TableChildren)},
+	}
+	for _, s := range slots {
+		if s.a != s.b {
+			return fmt.Sprintf("%s: %d %s != %d", path, s.a, s.name, s.b)
+		}
+	}
+
+	bChildren := b.Children()
+	for i, ac := range a.Children() {
+		bc := bChildren[i]
+		p := childPath(path, ac, i)
+		if !opts.IgnoreParent && (ac.parent == a) != (bc.parent == b) {
+			return fmt.Sprintf("%s: parent set %t != %t", p, ac.parent == a, bc.parent == b)
+		}
+		if diff := equalNode(ac, bc, p, opts); diff != "" {
+			return diff
+		}
+	}
+	return ""
+}
+
+func equalData(a, b DataTable) string {
+	keys := make([]DataKey, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		av, aok := a[k]
+		bv, bok := b[k]
+		switch {
+		case !aok:
+			return fmt.Sprintf("data %s <missing> != %v", k, bv)
+		case !bok:
+			return fmt.Sprintf("data %s %v != <missing>", k, av)
+		}
+		if ai, ok := a.GetInt(k); ok {
+			if bi, ok := b.GetInt(k); ok && ai == bi {
+				continue
+			}
+		}
+		if an, ok := av.(*Node); ok {
+			if bn, ok := bv.(*Node); ok {
+				if eq, _ := Equal(an, bn, EqualOptions{IgnorePosition: true, IgnoreParent: true}); eq {
+					continue
+				}
+			}
+		}
+		if !reflect.DeepEqual(av, bv) {
+			return fmt.Sprintf("data %s %v != %v", k, av, bv)
+		}
+	}
+	return ""
+}
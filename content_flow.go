@@ -4,10 +4,19 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 // FlowToMarkdown 将流式内容转换为 Markdown
 func FlowToMarkdown(ctx context.Context, n *Node) (string, error) {
+	s, err := flowToMarkdown(ctx, n)
+	if err != nil {
+		return "", withPosition(n, err)
+	}
+	return s, nil
+}
+
+func flowToMarkdown(ctx context.Context, n *Node) (string, error) {
 	switch n.Type {
 	case NodeParagraph:
 		return paragraphToMarkdown(ctx, n)
@@ -18,7 +27,7 @@ func FlowToMarkdown(ctx context.Context, n *Node) (string, error) {
 	case NodeCode:
 		return codeToMarkdown(ctx, n)
 	case NodeThematicBreak:
-		return "---\n\n", nil
+		return thematicBreakToMarkdown(ctx, n)
 	case NodeHTML:
 		return htmlToMarkdown(ctx, n)
 	case NodeYaml:
@@ -29,6 +38,8 @@ func FlowToMarkdown(ctx context.Context, n *Node) (string, error) {
 		return footnoteDefinitionToMarkdown(ctx, n)
 	case NodeList:
 		return ListToMarkdown(ctx, n)
+	case NodeTable:
+		return TableToMarkdown(ctx, n)
 	case NodeFootnote:
 		return footnoteToMarkdown(ctx, n)
 	default:
@@ -45,41 +56,128 @@ func paragraphToMarkdown(ctx context.Context, n *Node) (string, error) {
 }
 
 func headingToMarkdown(ctx context.Context, n *Node) (string, error) {
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return "", err
+	}
 	level, _ := n.Data.GetInt(NDK_Depth)
 	content, err := phrasingChildrenToMarkdown(ctx, n)
 	if err != nil {
 		return "", err
 	}
-	return strings.Repeat("#", level) + " " + content + "\n\n", nil
+	// ATX 标题只能占一行，内容中有换行的 1、2 级标题同样使用 setext 风格
+	if (opts.Setext || strings.Contains(content, "\n")) && level <= 2 && content != "" {
+		// setext 标题的第一行位于行首，需要按行首重新转义
+		if content, err = phrasingToMarkdown(ctx, n, true); err != nil {
+			return "", err
+		}
+		// setext 下划线与标题最后一行等宽
+		lines := strings.Split(content, "\n")
+		underline := "="
+		if level == 2 {
+			underline = "-"
+		}
+		width := utf8.RuneCountInString(lines[len(lines)-1])
+		return content + "\n" + strings.Repeat(underline, max(width, 1)) + "\n\n", nil
+	}
+	marker := strings.Repeat("#", level)
+	content = escapeHeadingContent(escapeHeadingLineEndings(content))
+	if opts.CloseAtx {
+		return marker + " " + content + " " + marker + "\n\n", nil
+	}
+	return marker + " " + content + "\n\n", nil
 }
 
 func blockquoteToMarkdown(ctx context.Context, n *Node) (string, error) {
-	content, err := flowChildrenToMarkdown(ctx, n)
+	return containerToMarkdown(ctx, n)
+}
+
+func codeToMarkdown(ctx context.Context, n *Node) (string, error) {
+	opts, err := SerializeOptionsFrom(ctx)
 	if err != nil {
 		return "", err
 	}
-	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
-	for i, line := range lines {
-		if line != "" {
-			lines[i] = "> " + line
-		} else {
-			lines[i] = ">"
+	lang, _ := n.Data.GetString(NDK_Lang)
+	if lang == "" && opts.IndentedCode && canIndentCode(n.Value) && !continuesPrevious(n) {
+		lines := strings.Split(n.Value, "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = "    " + line
+			}
 		}
+		return strings.Join(lines, "\n") + "\n\n", nil
 	}
-	return strings.Join(lines, "\n") + "\n\n", nil
-}
-
-func codeToMarkdown(ctx context.Context, n *Node) (string, error) {
-	lang, _ := n.Data.GetString(NDK_Lang)
 	meta, _ := n.Data.GetString(NDK_Meta)
 	if meta != "" {
 		lang += " " + meta
 	}
-	return "```" + lang + "\n" + n.Value + "\n```\n\n", nil
+	// 反引号围栏的信息字符串中不能出现反引号
+	marker := opts.Fence
+	if marker == "`" && strings.Contains(lang, "`") {
+		marker = "~"
+	}
+	fence := strings.Repeat(marker, max(3, longestRun(n.Value, marker[0])+1))
+	return fence + lang + "\n" + n.Value + "\n" + fence + "\n\n", nil
+}
+
+// continuesPrevious 判断缩进代码块是否会被前一个兄弟节点吸收：
+// 列表和脚注定义会把缩进的行当作自身的内容，相邻的缩进代码块会合并成一个
+func continuesPrevious(n *Node) bool {
+	slot, i, err := n.locate()
+	if err != nil || slot != slotFlow || i == 0 {
+		return false
+	}
+	switch n.parent.FlowChildren[i-1].GetType() {
+	case NodeList, NodeFootnoteDefinition, NodeCode:
+		return true
+	}
+	return false
 }
 
+// canIndentCode 判断代码内容能否无损地输出为缩进代码块：不能为空白，首尾行也不能是空白行
+func canIndentCode(value string) bool {
+	if strings.TrimLeft(value, " \t\r\n") == "" {
+		return false
+	}
+	lines := strings.Split(value, "\n")
+	return !isBlankLine(lines[0]) && !isBlankLine(lines[len(lines)-1])
+}
+
+// longestRun 返回 s 中字符 c 最长的连续长度
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+func thematicBreakToMarkdown(ctx context.Context, n *Node) (string, error) {
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return "", err
+	}
+	sep := ""
+	if opts.RuleSpaces {
+		sep = " "
+	}
+	rule := strings.TrimSuffix(strings.Repeat(opts.Rule+sep, opts.RuleRepetition), sep)
+	return rule + "\n\n", nil
+}
+
+// htmlToMarkdown 按节点所在的位置输出：短语内容中的 HTML 原样输出，流式内容中的 HTML 与其他块一样以空行结束。
+// 只有没有父节点、无法确定位置时才根据内容判断
 func htmlToMarkdown(ctx context.Context, n *Node) (string, error) {
-	if IsInlineHTML(n) {
+	inline := IsInlineHTML(n)
+	if n.parent != nil {
+		inline = slotFor(n.parent.Type) == slotPhrasing
+	}
+	if inline {
 		return n.Value, nil // 内联 HTML
 	}
 	return n.Value + "\n\n", nil // 块级 HTML
@@ -90,37 +188,36 @@ func yamlToMarkdown(ctx context.Context, n *Node) (string, error) {
 }
 
 func definitionToMarkdown(ctx context.Context, n *Node) (string, error) {
-	identifier, ok := n.Data.GetString(NDK_Identifier)
-	if !ok {
+	if _, ok := n.Data.GetString(NDK_Identifier); !ok {
 		return "", fmt.Errorf("missing or invalid identifier for definition")
 	}
 	url, ok := n.Data.GetString(NDK_URL)
 	if !ok {
 		return "", fmt.Errorf("missing or invalid URL for definition")
 	}
-	title, _ := n.Data.GetString(NDK_Title)
-	if title != "" {
-		return fmt.Sprintf("[%s]: %s \"%s\"\n", identifier, url, title), nil
+	title, hasTitle := n.Data.GetString(NDK_Title)
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return "", err
+	}
+	// 使用源文中的标签，标识符是规范化后的结果，大小写和空白可能已经改变
+	label := escapeLabel(n.Label())
+	if hasTitle {
+		return fmt.Sprintf("[%s]: %s %s\n", label, formatDestination(url), formatTitle(title, opts.Quote)), nil
 	}
-	return fmt.Sprintf("[%s]: %s\n", identifier, url), nil
+	return fmt.Sprintf("[%s]: %s\n", label, formatDestination(url)), nil
 }
 
 func footnoteDefinitionToMarkdown(ctx context.Context, n *Node) (string, error) {
-	identifier, ok := n.Data.GetString(NDK_Identifier)
-	if !ok {
-		return "", fmt.Errorf("missing or invalid identifier for footnote definition")
-	}
-	content, err := flowChildrenToMarkdown(ctx, n)
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("[^%s]: %s\n\n", identifier, strings.TrimSpace(content)), nil
+	return containerToMarkdown(ctx, n)
 }
 
+// footnoteToMarkdown 使用 ^[内容] 形式的行内脚注语法，[^内容] 会被读成脚注引用；
+// 不支持行内脚注的解析器需要先用 NormalizeFootnotes 转换为脚注引用和脚注定义
 func footnoteToMarkdown(ctx context.Context, n *Node) (string, error) {
 	content, err := phrasingChildrenToMarkdown(ctx, n)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("[^%s]", content), nil
+	return "^[" + content + "]", nil
 }
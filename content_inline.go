@@ -3,57 +3,173 @@ package mdast
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // InlineToMarkdown 将内联元素转换为 Markdown
 func InlineToMarkdown(ctx context.Context, n *Node) (string, error) {
+	s, err := inlineToMarkdown(ctx, n)
+	if err != nil {
+		return "", withPosition(n, err)
+	}
+	return s, nil
+}
+
+func inlineToMarkdown(ctx context.Context, n *Node) (string, error) {
 	switch n.Type {
 	case NodeText:
-		return n.Value, nil
+		return escapeText(n.Value, textContext{}), nil
 	case NodeEmphasis:
+		opts, err := SerializeOptionsFrom(ctx)
+		if err != nil {
+			return "", err
+		}
 		content, err := phrasingChildrenToMarkdown(ctx, n)
 		if err != nil {
 			return "", err
 		}
-		return "*" + content + "*", nil
+		marker := emphasisMarker(n, opts.Emphasis)
+		return marker + escapeEdgeWhitespace(content) + marker, nil
 	case NodeStrong:
+		opts, err := SerializeOptionsFrom(ctx)
+		if err != nil {
+			return "", err
+		}
 		content, err := phrasingChildrenToMarkdown(ctx, n)
 		if err != nil {
 			return "", err
 		}
-		return "**" + content + "**", nil
+		marker := opts.Strong
+		if marker == "_" && intraword(n) {
+			marker = "*"
+		}
+		marker = strings.Repeat(marker, 2)
+		return marker + escapeEdgeWhitespace(content) + marker, nil
 	case NodeDelete:
 		content, err := phrasingChildrenToMarkdown(ctx, n)
 		if err != nil {
 			return "", err
 		}
-		return "~~" + content + "~~", nil
+		return "~~" + escapeEdgeWhitespace(content) + "~~", nil
 	case NodeLink:
 		return linkToMarkdown(ctx, n)
 	case NodeImage:
 		return imageToMarkdown(ctx, n)
 	case NodeInlineCode:
-		return "`" + n.Value + "`", nil
+		return inlineCodeToMarkdown(n), nil
 	case NodeBreak:
-		return "\n", nil
+		// 表格单元格不能跨行，改用 HTML 换行
+		if inTableCell(n) {
+			return "<br>", nil
+		}
+		// 使用反斜杠形式的硬换行，行尾空格容易被编辑器删掉
+		return "\\\n", nil
 	case NodeLinkReference:
 		return linkReferenceToMarkdown(ctx, n)
 	case NodeImageReference:
 		return imageReferenceToMarkdown(ctx, n)
 	case NodeFootnoteReference:
-		identifier, ok := n.Data.GetString(NDK_Identifier)
-		if !ok {
+		if _, ok := n.Data.GetString(NDK_Identifier); !ok {
 			return "", fmt.Errorf("missing or invalid identifier for footnote reference")
 		}
-		return "[^" + identifier + "]", nil
+		return "[^" + escapeLabel(n.Label()) + "]", nil
 	case NodeFootnote:
 		return footnoteToMarkdown(ctx, n)
+	case NodeHTML:
+		// 行内 HTML 原样输出
+		return n.Value, nil
 	default:
 		return "", fmt.Errorf("unknown inline node type: %s", n.Type)
 	}
 }
 
+// emphasisMarker 选择强调的标记：单词内部的 "_" 不能形成强调，改用 "*"；
+// 强调位于使用同一标记的外层强调边缘时换用另一种标记，否则两层强调会被解析为加粗
+func emphasisMarker(n *Node, preferred string) string {
+	other := "_"
+	if preferred == "_" {
+		other = "*"
+	}
+	for _, marker := range []string{preferred, other} {
+		if marker == "_" && intraword(n) {
+			continue
+		}
+		if p := n.parent; p != nil && p.Type == NodeEmphasis && atEdge(n) && emphasisMarker(p, preferred) == marker {
+			continue
+		}
+		return marker
+	}
+	return "*"
+}
+
+// intraword 判断节点前后紧挨着的兄弟文本是否为字母或数字
+func intraword(n *Node) bool {
+	p := n.parent
+	if p == nil {
+		return false
+	}
+	i := slices.Index(p.PhrasingChildren, PhrasingContent(n))
+	if i < 0 {
+		return false
+	}
+	if i > 0 {
+		if prev := p.PhrasingChildren[i-1].(*Node); prev.Type == NodeText && prev.Value != "" {
+			r, _ := utf8.DecodeLastRuneInString(prev.Value)
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return true
+			}
+		}
+	}
+	if i+1 < len(p.PhrasingChildren) {
+		if next := p.PhrasingChildren[i+1].(*Node); next.Type == NodeText && next.Value != "" {
+			r, _ := utf8.DecodeRuneInString(next.Value)
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// atEdge 判断节点是否为父节点的第一个或最后一个短语子节点
+func atEdge(n *Node) bool {
+	children := n.parent.PhrasingChildren
+	return len(children) > 0 && (children[0] == PhrasingContent(n) || children[len(children)-1] == PhrasingContent(n))
+}
+
+// inlineCodeToMarkdown 选择内容中没有出现过的反引号串作为分隔符，必要时在两侧补空格
+func inlineCodeToMarkdown(n *Node) string {
+	value := n.Value
+	runs := map[int]bool{}
+	for i := 0; i < len(value); {
+		j := i
+		for j < len(value) && value[j] == '`' {
+			j++
+		}
+		if j > i {
+			runs[j-i] = true
+			i = j
+		} else {
+			i++
+		}
+	}
+	size := 1
+	for runs[size] {
+		size++
+	}
+	// 解析时首尾各去掉一个空格，内容以反引号开头或结尾、或首尾都是空格时需要补空格
+	padded := strings.HasPrefix(value, "`") || strings.HasSuffix(value, "`") ||
+		(len(value) > 1 && value[0] == ' ' && value[len(value)-1] == ' ' && strings.Trim(value, " ") != "")
+	if padded {
+		value = " " + value + " "
+	}
+	fence := strings.Repeat("`", size)
+	return fence + value + fence
+}
+
 func linkToMarkdown(ctx context.Context, n *Node) (string, error) {
 	text, err := phrasingChildrenToMarkdown(ctx, n)
 	if err != nil {
@@ -65,13 +181,14 @@ func linkToMarkdown(ctx context.Context, n *Node) (string, error) {
 		return "", fmt.Errorf("missing or invalid URL for link")
 	}
 
-	title, _ := n.Data.GetString(NDK_Title)
-	// 注意这里我们不检查 ok，因为 title 是可选的
+	// title 是可选的，只有设置了才输出
+	title, hasTitle := n.Data.GetString(NDK_Title)
 
-	if title != "" {
-		return fmt.Sprintf("[%s](%s \"%s\")", text, url, title), nil
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("[%s](%s)", text, url), nil
+	return "[" + text + "]" + formatResource(url, title, hasTitle, opts.Quote), nil
 }
 
 func imageToMarkdown(ctx context.Context, n *Node) (string, error) {
@@ -85,16 +202,16 @@ func imageToMarkdown(ctx context.Context, n *Node) (string, error) {
 		return "", fmt.Errorf("missing or invalid URL for image")
 	}
 
-	title, _ := n.Data.GetString(NDK_Title)
-	if title != "" {
-		return fmt.Sprintf("![%s](%s \"%s\")", alt, url, title), nil
+	title, hasTitle := n.Data.GetString(NDK_Title)
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("![%s](%s)", alt, url), nil
+	return "![" + escapeText(alt, textContext{}) + "]" + formatResource(url, title, hasTitle, opts.Quote), nil
 }
 
 func linkReferenceToMarkdown(ctx context.Context, n *Node) (string, error) {
-	identifier, ok := n.Data.GetString(NDK_Identifier)
-	if !ok {
+	if _, ok := n.Data.GetString(NDK_Identifier); !ok {
 		return "", fmt.Errorf("missing or invalid identifier for link reference")
 	}
 
@@ -103,24 +220,24 @@ func linkReferenceToMarkdown(ctx context.Context, n *Node) (string, error) {
 		return "", fmt.Errorf("missing or invalid reference type for link reference")
 	}
 
-	label, err := phrasingChildrenToMarkdown(ctx, n)
-	if err != nil {
-		return "", err
+	label := escapeLabel(n.Label())
+	// 简写和折叠形式的标签就是链接文本，只有标签解析后与子节点相同时才能原样输出，否则改用完整形式
+	if referenceType != ReferenceFull && labelMatches(label, n.PhrasingChildren) {
+		if referenceType == ReferenceShortcut {
+			return "[" + label + "]", nil
+		}
+		return "[" + label + "][]", nil
 	}
 
-	switch referenceType {
-	case ReferenceShortcut:
-		return fmt.Sprintf("[%s]", label), nil
-	case ReferenceCollapsed:
-		return fmt.Sprintf("[%s][]", label), nil
-	default: // full
-		return fmt.Sprintf("[%s][%s]", label, identifier), nil
+	text, err := phrasingChildrenToMarkdown(ctx, n)
+	if err != nil {
+		return "", err
 	}
+	return "[" + text + "][" + label + "]", nil
 }
 
 func imageReferenceToMarkdown(ctx context.Context, n *Node) (string, error) {
-	identifier, ok := n.Data.GetString(NDK_Identifier)
-	if !ok {
+	if _, ok := n.Data.GetString(NDK_Identifier); !ok {
 		return "", fmt.Errorf("missing or invalid identifier for image reference")
 	}
 
@@ -134,14 +251,84 @@ func imageReferenceToMarkdown(ctx context.Context, n *Node) (string, error) {
 		return "", fmt.Errorf("missing or invalid reference type for image reference")
 	}
 
-	switch referenceType {
-	case ReferenceShortcut:
-		return fmt.Sprintf("![%s]", alt), nil
-	case ReferenceCollapsed:
-		return fmt.Sprintf("![%s][]", alt), nil
-	default: // full
-		return fmt.Sprintf("![%s][%s]", alt, identifier), nil
+	label := escapeLabel(n.Label())
+	// 简写和折叠形式的替代文本由标签得出，标签的纯文本与 alt 不同时改用完整形式
+	if referenceType != ReferenceFull && labelAlt(label) == alt {
+		if referenceType == ReferenceShortcut {
+			return "![" + label + "]", nil
+		}
+		return "![" + label + "][]", nil
 	}
+	return "![" + escapeText(alt, textContext{}) + "][" + label + "]", nil
+}
+
+// escapeLabel 转义标签中未转义的方括号，源文中解析出的标签不含这样的方括号，原样返回
+func escapeLabel(label string) string {
+	var sb strings.Builder
+	for i := 0; i < len(label); i++ {
+		switch c := label[i]; {
+		case c == '\\' && i+1 < len(label):
+			sb.WriteByte(c)
+			i++
+			sb.WriteByte(label[i])
+		case c == '[' || c == ']':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// parseLabel 按行内内容解析标签，分别尝试不启用和启用 GFM 扩展
+func parseLabel(label string) [][]PhrasingContent {
+	return [][]PhrasingContent{
+		newInlineParser(false).parse(label, sourceMap{}),
+		newInlineParser(true).parse(label, sourceMap{}),
+	}
+}
+
+// labelMatches 判断标签解析后的短语内容是否与 children 相同
+func labelMatches(label string, children []PhrasingContent) bool {
+	want := &Node{Type: NodeParagraph, PhrasingChildren: children}
+	// 行内解析会去掉首尾空白，比较前把标签两端的空白补回去
+	lead := label[:len(label)-len(strings.TrimLeft(label, " \t"))]
+	trail := label[len(strings.TrimRight(label, " \t")):]
+	for _, parsed := range parseLabel(label) {
+		parsed = padPhrasing(parsed, lead, trail)
+		if ok, _ := Equal(want, &Node{Type: NodeParagraph, PhrasingChildren: parsed}, EqualOptions{IgnorePosition: true, IgnoreParent: true}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// padPhrasing 在短语内容两端补上空白，与相邻文本节点合并
+func padPhrasing(children []PhrasingContent, lead, trail string) []PhrasingContent {
+	if len(children) == 0 || lead == "" && trail == "" {
+		return children
+	}
+	if lead != "" {
+		if first, ok := children[0].(*Node); ok && first.Type == NodeText {
+			first.Value = lead + first.Value
+		} else {
+			children = append([]PhrasingContent{NewText(lead)}, children...)
+		}
+	}
+	if trail != "" {
+		if last, ok := children[len(children)-1].(*Node); ok && last.Type == NodeText {
+			last.Value += trail
+		} else {
+			children = append(children, NewText(trail))
+		}
+	}
+	return children
+}
+
+// labelAlt 返回标签作为图片描述时得到的替代文本
+func labelAlt(label string) string {
+	return phrasingPlainText(newInlineParser(false).parse(label, sourceMap{}))
 }
 
 // IsInlineHTML 判断给定的 HTML 节点是否为内联元素
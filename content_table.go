@@ -2,49 +2,139 @@ package mdast
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"unicode"
 )
 
-// TableToMarkdown 将表格内容转换为 Markdown
+// TableToMarkdown 将表格内容转换为 Markdown。列数以表头行为准，其余行多出的单元格被截掉、缺少的补空单元格，
+// 单元格内容中的竖线会被转义为 \|
 func TableToMarkdown(ctx context.Context, n *Node) (string, error) {
-	var result strings.Builder
-	alignments, ok := n.Data[NDK_Align].([]AlignType)
-	if !ok {
-		alignments = []AlignType{}
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(n.TableChildren) == 0 {
+		return "\n", nil
 	}
 
+	rows := make([][]string, len(n.TableChildren))
 	for i, row := range n.TableChildren {
-		rowContent, err := TableRowToMarkdown(ctx, row.(*Node))
+		cells, err := tableChildrenToMarkdownSlice(ctx, row.(*Node))
 		if err != nil {
-			return "", err
+			return "", withPosition(row.(*Node), err)
 		}
-		result.WriteString(rowContent + "\n")
-		if i == 0 {
-			result.WriteString("|")
+		rows[i] = cells
+	}
+	columns := len(rows[0])
+	for i := range rows {
+		// GFM 会忽略多出的单元格，补齐缺少的单元格
+		if len(rows[i]) > columns {
+			rows[i] = rows[i][:columns]
+		}
+		for len(rows[i]) < columns {
+			rows[i] = append(rows[i], "")
+		}
+	}
 
-			for j := range n.TableChildren[0].(*Node).TableChildren {
-				align := AlignNone
-				if j < len(alignments) {
-					align = alignments[j]
-				}
-				switch align {
-				case AlignLeft:
-					result.WriteString(" :--- |")
-				case AlignRight:
-					result.WriteString(" ---: |")
-				case AlignCenter:
-					result.WriteString(" :---: |")
-				default:
-					result.WriteString(" --- |")
+	aligns, _ := n.Data.GetAlignTypes(NDK_Align)
+	align := make([]AlignType, columns)
+	copy(align, aligns)
+
+	// widths 为 nil 时不对齐，各单元格按原样输出
+	var widths []int
+	if opts.TablePipeAlign {
+		widths = make([]int, columns)
+		for j := range widths {
+			widths[j] = delimiterWidth(align[j], opts.TableCompact)
+			for _, cells := range rows {
+				widths[j] = max(widths[j], displayWidth(cells[j]))
+			}
+		}
+	}
+
+	var result strings.Builder
+	for i, cells := range rows {
+		writeTableRow(&result, cells, align, widths, opts.TableCompact)
+		if i == 0 {
+			delimiters := make([]string, columns)
+			for j := range delimiters {
+				width := delimiterWidth(align[j], opts.TableCompact)
+				if widths != nil {
+					width = widths[j]
 				}
+				delimiters[j] = delimiterCell(align[j], width)
 			}
-			result.WriteString("\n")
+			writeTableRow(&result, delimiters, nil, nil, opts.TableCompact)
 		}
 	}
 	result.WriteString("\n")
 	return result.String(), nil
 }
 
+// writeTableRow 输出一行，widths 非空时按 align 补齐到对应宽度
+func writeTableRow(sb *strings.Builder, cells []string, align []AlignType, widths []int, compact bool) {
+	pad := " "
+	if compact {
+		pad = ""
+	}
+	sb.WriteString("|")
+	for j, cell := range cells {
+		left, right := 0, 0
+		if widths != nil {
+			gap := widths[j] - displayWidth(cell)
+			switch align[j] {
+			case AlignRight:
+				left = gap
+			case AlignCenter:
+				left = gap / 2
+				right = gap - left
+			default:
+				right = gap
+			}
+		}
+		sb.WriteString(pad)
+		sb.WriteString(strings.Repeat(" ", left))
+		sb.WriteString(cell)
+		sb.WriteString(strings.Repeat(" ", right))
+		sb.WriteString(pad)
+		sb.WriteString("|")
+	}
+	sb.WriteString("\n")
+}
+
+// delimiterWidth 返回分隔行单元格的最小宽度
+func delimiterWidth(align AlignType, compact bool) int {
+	if !compact {
+		if align == AlignCenter {
+			return 5
+		}
+		if align == AlignLeft || align == AlignRight {
+			return 4
+		}
+		return 3
+	}
+	if align == AlignCenter {
+		return 3
+	}
+	if align == AlignLeft || align == AlignRight {
+		return 2
+	}
+	return 1
+}
+
+// delimiterCell 返回宽度为 width 的分隔行单元格，如 ":---"、":---:"、"---:"
+func delimiterCell(align AlignType, width int) string {
+	cell := []byte(strings.Repeat("-", width))
+	if align == AlignLeft || align == AlignCenter {
+		cell[0] = ':'
+	}
+	if align == AlignRight || align == AlignCenter {
+		cell[width-1] = ':'
+	}
+	return string(cell)
+}
+
 func TableRowToMarkdown(ctx context.Context, n *Node) (string, error) {
 	cells, err := tableChildrenToMarkdownSlice(ctx, n)
 	if err != nil {
@@ -53,8 +143,27 @@ func TableRowToMarkdown(ctx context.Context, n *Node) (string, error) {
 	return "| " + strings.Join(cells, " | ") + " |", nil
 }
 
+// TableCellToMarkdown 输出单元格内容，其中的竖线会被转义为 \|，代码片段中的竖线也不例外；
+// 硬换行输出为 <br>，其余换行无法在单元格中表示，返回错误
 func TableCellToMarkdown(ctx context.Context, n *Node) (string, error) {
-	return phrasingChildrenToMarkdown(ctx, n)
+	content, err := phrasingChildrenToMarkdown(ctx, n)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(content, "\n") {
+		return "", fmt.Errorf("table cell content cannot contain line endings")
+	}
+	return strings.ReplaceAll(content, "|", `\|`), nil
+}
+
+// inTableCell 判断节点是否位于表格单元格中
+func inTableCell(n *Node) bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if p.Type == NodeTableCell {
+			return true
+		}
+	}
+	return false
 }
 
 func tableChildrenToMarkdownSlice(ctx context.Context, n *Node) ([]string, error) {
@@ -62,9 +171,58 @@ func tableChildrenToMarkdownSlice(ctx context.Context, n *Node) ([]string, error
 	for i, child := range n.TableChildren {
 		content, err := TableCellToMarkdown(ctx, child.(*Node))
 		if err != nil {
-			return nil, err
+			return nil, withPosition(child.(*Node), err)
 		}
 		result[i] = content
 	}
 	return result, nil
 }
+
+// wideRanges 是按两列显示的字符范围，包括东亚宽字符、全角字符和常见 emoji
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE30, 0xFE4F}, {0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6}, {0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F900, 0x1F9FF}, {0x1FA70, 0x1FAFF},
+	{0x20000, 0x3FFFD},
+}
+
+// displayWidth 返回字符串在等宽字体下占用的列数。组合字符、零宽连接符、变体选择符和肤色修饰符不占宽度，
+// 零宽连接符连接的 emoji 序列与一对区域指示符（国旗）都按一个字符计算
+func displayWidth(s string) int {
+	width := 0
+	joined, regional := false, false
+	for _, r := range s {
+		switch {
+		case r == 0x200D:
+			joined = true
+			continue
+		case joined || unicode.In(r, unicode.Mn, unicode.Me) || (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0x1F3FB && r <= 0x1F3FF):
+		case r >= 0x1F1E6 && r <= 0x1F1FF:
+			// 区域指示符两两组成一个国旗
+			if !regional {
+				width += 2
+			}
+			regional = !regional
+			joined = false
+			continue
+		case isWide(r):
+			width += 2
+		default:
+			width++
+		}
+		joined, regional = false, false
+	}
+	return width
+}
+
+func isWide(r rune) bool {
+	for _, wr := range wideRanges {
+		if r < wr[0] {
+			return false
+		}
+		if r <= wr[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,7 @@
 package mdast
 
+import "math"
+
 // DataKey 定义 Data 中的键
 type DataKey string
 
@@ -22,6 +24,8 @@ const (
 	NDK_Spread        DataKey = "spread"
 	NDK_Checked       DataKey = "checked"
 	NDK_Align         DataKey = "align"
+	// NDK_ID 是标题的锚点 id，由 HeadingIDs 生成，不属于 mdast 规范的属性，JSON 中放在 data 字段
+	NDK_ID DataKey = "id"
 )
 
 // GetString 从 DataTable 中获取字符串值
@@ -34,14 +38,49 @@ func (dt DataTable) GetString(key DataKey) (string, bool) {
 	return strValue, ok
 }
 
-// GetInt 从 DataTable 中获取整数值
+// GetInt 从 DataTable 中获取整数值，其他整数类型和没有小数部分的浮点数（如 JSON 解码的结果）也会被接受，
+// 超出 int 范围的值返回 false
 func (dt DataTable) GetInt(key DataKey) (int, bool) {
 	value, ok := dt[key]
 	if !ok {
 		return 0, false
 	}
-	intValue, ok := value.(int)
-	return intValue, ok
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		if v >= math.MinInt && v <= math.MaxInt {
+			return int(v), true
+		}
+	case uint:
+		if v <= math.MaxInt {
+			return int(v), true
+		}
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		if uint64(v) <= math.MaxInt {
+			return int(v), true
+		}
+	case uint64:
+		if v <= math.MaxInt {
+			return int(v), true
+		}
+	case float64:
+		// 只接受能精确转换的整数值，超出 int 范围或带小数部分时返回 false
+		if v == math.Trunc(v) && v >= math.MinInt && v < -float64(math.MinInt) {
+			return int(v), true
+		}
+	}
+	return 0, false
 }
 
 // GetBool 从 DataTable 中获取布尔值
@@ -60,8 +99,32 @@ func (dt DataTable) GetAlignType(key DataKey) (AlignType, bool) {
 	if !ok {
 		return AlignNone, false
 	}
-	alignValue, ok := value.(AlignType)
-	return alignValue, ok
+	switch v := value.(type) {
+	case AlignType:
+		return v, true
+	case string:
+		return AlignType(v), true
+	}
+	return AlignNone, false
+}
+
+// GetAlignTypes 从 DataTable 中获取 AlignType 列表，也接受字符串列表
+func (dt DataTable) GetAlignTypes(key DataKey) ([]AlignType, bool) {
+	value, ok := dt[key]
+	if !ok {
+		return nil, false
+	}
+	switch v := value.(type) {
+	case []AlignType:
+		return v, true
+	case []string:
+		aligns := make([]AlignType, len(v))
+		for i, a := range v {
+			aligns[i] = AlignType(a)
+		}
+		return aligns, true
+	}
+	return nil, false
 }
 
 // GetReferenceType 从 DataTable 中获取 ReferenceType 值
@@ -70,6 +133,11 @@ func (dt DataTable) GetReferenceType(key DataKey) (ReferenceType, bool) {
 	if !ok {
 		return "", false
 	}
-	refValue, ok := value.(ReferenceType)
-	return refValue, ok
+	switch v := value.(type) {
+	case ReferenceType:
+		return v, true
+	case string:
+		return ReferenceType(v), true
+	}
+	return "", false
 }
@@ -0,0 +1,214 @@
+package mdast
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// reOrderedMarker 匹配行首会被解析为有序列表标记的数字
+	reOrderedMarker = regexp.MustCompile(`^[0-9]{1,9}[.)](?:[ \t\n]|$)`)
+	// reSetextLine 匹配整行都是 = 或 - 的 setext 下划线
+	reSetextLine = regexp.MustCompile(`^(?:=+|-+)[ \t]*(?:\n|$)`)
+)
+
+// textContext 描述文本节点在输出中的位置，用于判断哪些字符需要转义
+type textContext struct {
+	// atBreak 表示文本从行首开始输出
+	atBreak bool
+	// beforeLink 表示文本之后紧跟以 [ 开头的节点
+	beforeLink bool
+}
+
+// escapeText 转义文本中会被解析为 Markdown 语法的字符，保证序列化后再解析得到相同的文本
+func escapeText(value string, tc textContext) string {
+	var sb strings.Builder
+	lineStart := tc.atBreak
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if lineStart {
+			lineStart = false
+			// 空行会结束段落，写成字符引用
+			if c == '\n' {
+				sb.WriteString("&#10;")
+				continue
+			}
+			if replacement, n := escapeLineStart(value[i:]); n > 0 {
+				sb.WriteString(replacement)
+				i += n - 1
+				continue
+			}
+		}
+		switch c {
+		case '\n':
+			lineStart = true
+		case '*', '[', ']', '`', '<', '~':
+			sb.WriteByte('\\')
+		case '_':
+			// 单词内部的下划线不会形成强调
+			if i == 0 || i+1 == len(value) || !isAlnum(value[i-1]) || !isAlnum(value[i+1]) {
+				sb.WriteByte('\\')
+			}
+		case '\\':
+			if i+1 == len(value) || value[i+1] == '\n' || isEscapable(value[i+1]) {
+				sb.WriteByte('\\')
+			}
+		case '&':
+			if reEntityHere.MatchString(value[i:]) {
+				sb.WriteByte('\\')
+			}
+		case '!':
+			if i+1 == len(value) && tc.beforeLink {
+				sb.WriteByte('\\')
+			}
+		case ' ':
+			// 行尾空格会被丢弃或形成硬换行
+			if i+1 < len(value) && value[i+1] == '\n' {
+				sb.WriteString("&#x20;")
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// escapeEdgeWhitespace 把强调内容首尾的空格和制表符写成字符引用，否则分隔符无法形成强调
+func escapeEdgeWhitespace(content string) string {
+	refs := map[byte]string{' ': "&#x20;", '\t': "&#x9;"}
+	if content == "" {
+		return content
+	}
+	if ref, ok := refs[content[0]]; ok {
+		content = ref + content[1:]
+	}
+	if ref, ok := refs[content[len(content)-1]]; ok {
+		content = content[:len(content)-1] + ref
+	}
+	return content
+}
+
+// escapeLineStart 处理行首会开启块级结构的字符，返回替换文本和消耗的字节数，无需处理时返回 0
+func escapeLineStart(s string) (string, int) {
+	switch c := s[0]; {
+	case c == ' ':
+		return "&#x20;", 1
+	case c == '\t':
+		return "&#x9;", 1
+	case c == '>':
+		return `\>`, 1
+	case c == '#':
+		n := 0
+		for n < len(s) && s[n] == '#' {
+			n++
+		}
+		if n <= 6 && (n == len(s) || s[n] == ' ' || s[n] == '\t' || s[n] == '\n') {
+			return `\` + s[:n], n
+		}
+	case c == '-' || c == '+':
+		if len(s) == 1 || s[1] == ' ' || s[1] == '\t' || s[1] == '\n' || (c == '-' && reSetextLine.MatchString(s)) {
+			return `\` + string(c), 1
+		}
+	case c == '=':
+		if reSetextLine.MatchString(s) {
+			return `\=`, 1
+		}
+	case c >= '0' && c <= '9':
+		if m := reOrderedMarker.FindString(s); m != "" {
+			digits := strings.TrimRight(m, ".) \t\n")
+			return digits + `\`, len(digits)
+		}
+	}
+	return "", 0
+}
+
+// escapeHeadingContent 转义 ATX 标题末尾会被当作闭合序列的 #
+func escapeHeadingContent(content string) string {
+	trimmed := strings.TrimRight(content, "#")
+	if trimmed == content {
+		return content
+	}
+	if trimmed == "" || strings.HasSuffix(trimmed, " ") || strings.HasSuffix(trimmed, "\t") {
+		return trimmed + `\` + content[len(trimmed):]
+	}
+	return content
+}
+
+// escapeHeadingLineEndings 替换 ATX 标题内容中的换行：软换行写成字符引用，
+// 硬换行无法在 ATX 标题中表示，改为空格
+func escapeHeadingLineEndings(content string) string {
+	var sb strings.Builder
+	backslashes := 0
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n' && backslashes%2 == 1:
+			s := sb.String()
+			sb.Reset()
+			sb.WriteString(s[:len(s)-1] + " ")
+		case c == '\n':
+			sb.WriteString("&#10;")
+		default:
+			sb.WriteByte(c)
+		}
+		if c == '\\' {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+	}
+	return sb.String()
+}
+
+// escapeLiteral 转义链接地址和标题中的反斜杠与实体，extra 中的字符也会被反斜杠转义
+func escapeLiteral(value, extra string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '\\' && (i+1 == len(value) || isEscapable(value[i+1])):
+			sb.WriteByte('\\')
+		case c == '&' && reEntityHere.MatchString(value[i:]):
+			sb.WriteByte('\\')
+		case strings.IndexByte(extra, c) >= 0:
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// formatDestination 输出链接地址，包含空白、括号或尖括号时使用 <...> 形式
+func formatDestination(url string) string {
+	useAngle := url == ""
+	for i := 0; i < len(url); i++ {
+		if c := url[i]; c <= ' ' || c == '(' || c == ')' || c == '<' || c == '>' || c == 0x7f {
+			useAngle = true
+			break
+		}
+	}
+	if useAngle {
+		return "<" + escapeLiteral(url, "<>") + ">"
+	}
+	return escapeLiteral(url, "")
+}
+
+// formatTitle 输出链接标题，优先使用 quote，标题中只含该引号时改用另一种引号
+func formatTitle(title, quote string) string {
+	other := `'`
+	if quote == `'` {
+		other = `"`
+	}
+	if strings.Contains(title, quote) && !strings.Contains(title, other) {
+		quote = other
+	}
+	return quote + escapeLiteral(title, quote) + quote
+}
+
+// formatResource 输出 (url "title") 形式的链接资源，显式设置的空标题也会输出
+func formatResource(url, title string, hasTitle bool, quote string) string {
+	if !hasTitle {
+		return "(" + formatDestination(url) + ")"
+	}
+	return "(" + formatDestination(url) + " " + formatTitle(title, quote) + ")"
+}
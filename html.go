@@ -0,0 +1,590 @@
+package mdast
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HTMLOptions 控制 ToHTML 的渲染行为
+type HTMLOptions struct {
+	// AllowDangerousHTML 为 true 时原样输出 NodeHTML 节点，默认丢弃
+	AllowDangerousHTML bool
+	// ClobberPrefix 是脚注等生成的 id 的前缀，避免与页面中已有的 id 冲突，标题的 id 不加前缀
+	ClobberPrefix string
+	// FootnoteLabel 是脚注区块的标题
+	FootnoteLabel string
+	// FootnoteBackLabel 是脚注回链的无障碍标签，其中每个 "%s" 都会被替换为引用序号，如 1 或 1-2，
+	// 标签按普通文本处理，其他 % 原样保留
+	FootnoteBackLabel string
+}
+
+// HTMLOption 用于设置 HTMLOptions
+type HTMLOption func(*HTMLOptions)
+
+// WithDangerousHTML 允许输出原始 HTML
+func WithDangerousHTML() HTMLOption {
+	return func(o *HTMLOptions) {
+		o.AllowDangerousHTML = true
+	}
+}
+
+// WithClobberPrefix 设置生成 id 的前缀
+func WithClobberPrefix(prefix string) HTMLOption {
+	return func(o *HTMLOptions) {
+		o.ClobberPrefix = prefix
+	}
+}
+
+// WithFootnoteLabels 设置脚注区块标题和回链标签
+func WithFootnoteLabels(label, backLabel string) HTMLOption {
+	return func(o *HTMLOptions) {
+		o.FootnoteLabel = label
+		o.FootnoteBackLabel = backLabel
+	}
+}
+
+// ToHTML 将语法树渲染为 HTML，引用通过树中的定义解析，脚注以带回链的区块输出在末尾，
+// 文本和属性都会被转义，不安全协议的链接会被清空
+func ToHTML(ctx context.Context, n *Node, opts ...HTMLOption) (string, error) {
+	options := HTMLOptions{
+		ClobberPrefix:     "user-content-",
+		FootnoteLabel:     "Footnotes",
+		FootnoteBackLabel: "Back to reference %s",
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	r := &htmlRenderer{
+		options:   options,
+		index:     BuildIndex(n),
+		footnotes: map[string]*htmlFootnote{},
+	}
+	if err := r.node(ctx, n, nil); err != nil {
+		return "", err
+	}
+	if err := r.footnoteSection(ctx); err != nil {
+		return "", err
+	}
+	return r.sb.String(), nil
+}
+
+// htmlFootnote 记录一个被引用的脚注
+type htmlFootnote struct {
+	id     string
+	number int
+	refs   int
+	def    *Node
+}
+
+type htmlRenderer struct {
+	options   HTMLOptions
+	sb        strings.Builder
+	index     *Index
+	footnotes map[string]*htmlFootnote
+	order     []*htmlFootnote
+}
+
+func (r *htmlRenderer) out(s string) {
+	r.sb.WriteString(s)
+}
+
+// cr 保证后续输出从新的一行开始
+func (r *htmlRenderer) cr() {
+	if r.sb.Len() > 0 && !strings.HasSuffix(r.sb.String(), "\n") {
+		r.sb.WriteByte('\n')
+	}
+}
+
+func (r *htmlRenderer) children(ctx context.Context, n *Node) error {
+	for _, child := range n.Children() {
+		if err := r.node(ctx, child, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *htmlRenderer) node(ctx context.Context, n *Node, parent *Node) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return withPosition(n, r.render(ctx, n, parent))
+}
+
+func (r *htmlRenderer) render(ctx context.Context, n *Node, parent *Node) error {
+	switch n.Type {
+	case NodeRoot:
+		return r.children(ctx, n)
+	case NodeParagraph:
+		return r.block(ctx, "<p>", n, "</p>")
+	case NodeHeading:
+		depth, ok := n.Data.GetInt(NDK_Depth)
+		if !ok || depth < 1 || depth > 6 {
+			return fmt.Errorf("missing or invalid depth for heading")
+		}
+		// 标题的 id 由 HeadingIDs 生成，目录等链接直接指向它，所以不加 ClobberPrefix
+		open := fmt.Sprintf("<h%d>", depth)
+		if id, _ := n.Data.GetString(NDK_ID); id != "" {
+			open = fmt.Sprintf(`<h%d id="%s">`, depth, escapeHTML(id))
+		}
+		return r.block(ctx, open, n, fmt.Sprintf("</h%d>", depth))
+	case NodeBlockquote:
+		return r.block(ctx, "<blockquote>\n", n, "\n</blockquote>")
+	case NodeList:
+		return r.list(ctx, n)
+	case NodeListItem:
+		return r.listItem(ctx, n, parent)
+	case NodeCode:
+		r.cr()
+		if lang, _ := n.Data.GetString(NDK_Lang); lang != "" {
+			r.out(`<pre><code class="language-` + escapeHTML(lang) + `">`)
+		} else {
+			r.out("<pre><code>")
+		}
+		if n.Value != "" {
+			r.out(escapeHTML(n.Value) + "\n")
+		}
+		r.out("</code></pre>")
+		r.cr()
+	case NodeThematicBreak:
+		r.cr()
+		r.out("<hr>")
+		r.cr()
+	case NodeHTML:
+		if r.options.AllowDangerousHTML {
+			if parent == nil || len(parent.PhrasingChildren) == 0 {
+				r.cr()
+				r.out(n.Value)
+				r.cr()
+			} else {
+				r.out(n.Value)
+			}
+		}
+	case NodeTable:
+		return r.table(ctx, n)
+	case NodeDefinition, NodeFootnoteDefinition, NodeYaml:
+		// 定义只用于解析引用，脚注定义在末尾的脚注区块中输出
+	case NodeText:
+		r.out(escapeHTML(n.Value))
+	case NodeEmphasis:
+		return r.inline(ctx, "<em>", n, "</em>")
+	case NodeStrong:
+		return r.inline(ctx, "<strong>", n, "</strong>")
+	case NodeDelete:
+		return r.inline(ctx, "<del>", n, "</del>")
+	case NodeInlineCode:
+		r.out("<code>" + escapeHTML(n.Value) + "</code>")
+	case NodeBreak:
+		r.out("<br>\n")
+	case NodeLink:
+		return r.link(ctx, n, n)
+	case NodeImage:
+		r.image(n, n)
+	case NodeLinkReference:
+		def, ok := r.resolve(n)
+		if !ok {
+			return r.revertReference(ctx, n, "[")
+		}
+		return r.link(ctx, n, def)
+	case NodeImageReference:
+		def, ok := r.resolve(n)
+		if !ok {
+			return r.revertReference(ctx, n, "![")
+		}
+		r.image(n, def)
+	case NodeFootnoteReference:
+		identifier, ok := n.Data.GetString(NDK_Identifier)
+		if !ok {
+			return fmt.Errorf("missing or invalid identifier for footnote reference")
+		}
+		def, ok := r.index.FootnoteDefinition(identifier)
+		if !ok {
+			label, ok := n.Data.GetString(NDK_Label)
+			if !ok {
+				label = identifier
+			}
+			r.out("[^" + escapeHTML(label) + "]")
+			return nil
+		}
+		r.footnoteReference(normalizeLabel(identifier), def)
+	case NodeFootnote:
+		// 行内脚注没有标识符，按出现顺序生成
+		def := NewNode(NodeFootnoteDefinition)
+		para := NewNode(NodeParagraph)
+		para.PhrasingChildren = n.PhrasingChildren
+		def.AddFlowChild(para)
+		r.footnoteReference("inline-"+strconv.Itoa(len(r.order)+1), def)
+	default:
+		return fmt.Errorf("unknown node type: %s", n.Type)
+	}
+	return nil
+}
+
+func (r *htmlRenderer) block(ctx context.Context, open string, n *Node, close string) error {
+	r.cr()
+	r.out(open)
+	if err := r.children(ctx, n); err != nil {
+		return err
+	}
+	if strings.HasPrefix(close, "\n") {
+		r.cr()
+		close = close[1:]
+	}
+	r.out(close)
+	r.cr()
+	return nil
+}
+
+func (r *htmlRenderer) inline(ctx context.Context, open string, n *Node, close string) error {
+	r.out(open)
+	if err := r.children(ctx, n); err != nil {
+		return err
+	}
+	r.out(close)
+	return nil
+}
+
+// listLoose 判断列表是否为松散列表，松散列表中的段落保留 <p>
+func listLoose(list *Node) bool {
+	if spread, _ := list.Data.GetBool(NDK_Spread); spread {
+		return true
+	}
+	for _, child := range list.ListChildren {
+		if listItemSpread(child.(*Node)) {
+			return true
+		}
+	}
+	return false
+}
+
+func listItemSpread(item *Node) bool {
+	if spread, ok := item.Data.GetBool(NDK_Spread); ok {
+		return spread
+	}
+	return len(item.FlowChildren) > 1
+}
+
+func listItemLoose(item, list *Node) bool {
+	if list != nil && list.Type == NodeList {
+		return listLoose(list)
+	}
+	return listItemSpread(item)
+}
+
+func (r *htmlRenderer) list(ctx context.Context, n *Node) error {
+	ordered := n.Ordered()
+	tag := "ul"
+	attrs := ""
+	if ordered {
+		tag = "ol"
+		if start, ok := n.Data.GetInt(NDK_Start); ok && start != 1 {
+			attrs += fmt.Sprintf(` start="%d"`, start)
+		}
+	}
+	for _, child := range n.ListChildren {
+		if _, ok := child.(*Node).Data.GetBool(NDK_Checked); ok {
+			attrs += ` class="contains-task-list"`
+			break
+		}
+	}
+	r.cr()
+	r.out("<" + tag + attrs + ">\n")
+	if err := r.children(ctx, n); err != nil {
+		return err
+	}
+	r.cr()
+	r.out("</" + tag + ">")
+	r.cr()
+	return nil
+}
+
+func (r *htmlRenderer) listItem(ctx context.Context, n *Node, parent *Node) error {
+	loose := listItemLoose(n, parent)
+	checked, task := n.Data.GetBool(NDK_Checked)
+	r.cr()
+	if task {
+		r.out(`<li class="task-list-item">`)
+	} else {
+		r.out("<li>")
+	}
+	if loose {
+		r.out("\n")
+	}
+	for i, child := range n.FlowChildren {
+		child := child.(*Node)
+		checkbox := ""
+		if i == 0 && task {
+			checkbox = `<input type="checkbox" disabled> `
+			if checked {
+				checkbox = `<input type="checkbox" checked disabled> `
+			}
+		}
+		if child.Type != NodeParagraph {
+			r.out(checkbox)
+			if err := r.node(ctx, child, n); err != nil {
+				return err
+			}
+			continue
+		}
+		// 紧凑列表项中的段落不输出 <p>，复选框放进第一个段落中
+		if loose {
+			r.cr()
+			r.out("<p>")
+		}
+		r.out(checkbox)
+		if err := r.children(ctx, child); err != nil {
+			return withPosition(child, err)
+		}
+		if loose {
+			r.out("</p>\n")
+		}
+	}
+	if loose {
+		r.cr()
+	}
+	r.out("</li>")
+	r.cr()
+	return nil
+}
+
+func (r *htmlRenderer) table(ctx context.Context, n *Node) error {
+	align, _ := n.Data.GetAlignTypes(NDK_Align)
+	if len(n.TableChildren) == 0 {
+		return nil
+	}
+	// 各行的单元格数量以表头行为准，缺少的补空单元格，多余的丢弃
+	columns := len(n.TableChildren[0].(*Node).TableChildren)
+	r.cr()
+	r.out("<table>\n")
+	for i, row := range n.TableChildren {
+		row := row.(*Node)
+		tag := "td"
+		if i == 0 {
+			tag = "th"
+			r.out("<thead>\n")
+		} else if i == 1 {
+			r.out("<tbody>\n")
+		}
+		r.out("<tr>\n")
+		for j := 0; j < columns; j++ {
+			r.out("<" + tag)
+			if j < len(align) && align[j] != AlignNone {
+				r.out(` align="` + string(align[j]) + `"`)
+			}
+			r.out(">")
+			if j < len(row.TableChildren) {
+				cell := row.TableChildren[j].(*Node)
+				if err := r.children(ctx, cell); err != nil {
+					return withPosition(cell, err)
+				}
+			}
+			r.out("</" + tag + ">\n")
+		}
+		r.out("</tr>\n")
+		if i == 0 {
+			r.out("</thead>\n")
+		}
+	}
+	if len(n.TableChildren) > 1 {
+		r.out("</tbody>\n")
+	}
+	r.out("</table>")
+	r.cr()
+	return nil
+}
+
+// resolve 查找引用对应的定义
+func (r *htmlRenderer) resolve(n *Node) (*Node, bool) {
+	identifier, ok := n.Data.GetString(NDK_Identifier)
+	if !ok {
+		return nil, false
+	}
+	def, ok := r.index.Definition(identifier)
+	return def, ok
+}
+
+// revertReference 将无法解析的引用还原为原始的 Markdown 文本
+func (r *htmlRenderer) revertReference(ctx context.Context, n *Node, open string) error {
+	r.out(escapeHTML(open))
+	if n.Type == NodeImageReference {
+		alt, _ := n.Data.GetString(NDK_Alt)
+		r.out(escapeHTML(alt))
+	} else if err := r.children(ctx, n); err != nil {
+		return err
+	}
+	r.out("]")
+	referenceType, _ := n.Data.GetReferenceType(NDK_ReferenceType)
+	switch referenceType {
+	case ReferenceCollapsed:
+		r.out("[]")
+	case ReferenceFull:
+		label, ok := n.Data.GetString(NDK_Label)
+		if !ok {
+			label, _ = n.Data.GetString(NDK_Identifier)
+		}
+		r.out("[" + escapeHTML(label) + "]")
+	}
+	return nil
+}
+
+func (r *htmlRenderer) link(ctx context.Context, n *Node, target *Node) error {
+	url, _ := target.Data.GetString(NDK_URL)
+	r.out(`<a href="` + escapeHTML(sanitizeURL(url, safeLinkProtocols)) + `"`)
+	if title, _ := target.Data.GetString(NDK_Title); title != "" {
+		r.out(` title="` + escapeHTML(title) + `"`)
+	}
+	r.out(">")
+	if err := r.children(ctx, n); err != nil {
+		return err
+	}
+	r.out("</a>")
+	return nil
+}
+
+func (r *htmlRenderer) image(n *Node, target *Node) {
+	url, _ := target.Data.GetString(NDK_URL)
+	alt, _ := n.Data.GetString(NDK_Alt)
+	r.out(`<img src="` + escapeHTML(sanitizeURL(url, safeImageProtocols)) + `" alt="` + escapeHTML(alt) + `"`)
+	if title, _ := target.Data.GetString(NDK_Title); title != "" {
+		r.out(` title="` + escapeHTML(title) + `"`)
+	}
+	r.out(">")
+}
+
+// footnoteReference 输出脚注引用，脚注按第一次被引用的顺序编号
+func (r *htmlRenderer) footnoteReference(id string, def *Node) {
+	fn, ok := r.footnotes[id]
+	if !ok {
+		fn = &htmlFootnote{id: id, number: len(r.order) + 1, def: def}
+		r.footnotes[id] = fn
+		r.order = append(r.order, fn)
+	}
+	fn.refs++
+	prefix := r.options.ClobberPrefix
+	safeID := escapeHTML(normalizeURL(id))
+	refID := prefix + "fnref-" + safeID
+	if fn.refs > 1 {
+		refID += "-" + strconv.Itoa(fn.refs)
+	}
+	r.out(fmt.Sprintf(`<sup><a href="#%sfn-%s" id="%s" data-footnote-ref="" aria-describedby="footnote-label">%d</a></sup>`,
+		prefix, safeID, refID, fn.number))
+}
+
+// footnoteSection 在末尾输出被引用过的脚注定义及其回链
+func (r *htmlRenderer) footnoteSection(ctx context.Context) error {
+	if len(r.order) == 0 {
+		return nil
+	}
+	prefix := r.options.ClobberPrefix
+	r.cr()
+	r.out(`<section data-footnotes="" class="footnotes"><h2 class="sr-only" id="footnote-label">` +
+		escapeHTML(r.options.FootnoteLabel) + "</h2>\n<ol>\n")
+	// 脚注内容中可能出现新的脚注引用，r.order 会在循环中增长
+	for i := 0; i < len(r.order); i++ {
+		fn := r.order[i]
+		safeID := escapeHTML(normalizeURL(fn.id))
+		var backrefs strings.Builder
+		for k := 1; k <= fn.refs; k++ {
+			refID := prefix + "fnref-" + safeID
+			number := strconv.Itoa(fn.number)
+			if k > 1 {
+				refID += "-" + strconv.Itoa(k)
+				number += "-" + strconv.Itoa(k)
+			}
+			label := strings.ReplaceAll(r.options.FootnoteBackLabel, "%s", number)
+			backrefs.WriteString(fmt.Sprintf(` <a href="#%s" data-footnote-backref="" aria-label="%s" class="data-footnote-backref">↩`,
+				refID, escapeHTML(label)))
+			if k > 1 {
+				backrefs.WriteString(fmt.Sprintf("<sup>%d</sup>", k))
+			}
+			backrefs.WriteString("</a>")
+		}
+
+		r.out(`<li id="` + prefix + "fn-" + safeID + `">` + "\n")
+		children := fn.def.FlowChildren
+		for j, child := range children {
+			child := child.(*Node)
+			if j == len(children)-1 && child.Type == NodeParagraph {
+				// 回链放在最后一个段落的末尾
+				r.out("<p>")
+				if err := r.children(ctx, child); err != nil {
+					return withPosition(child, err)
+				}
+				r.out(backrefs.String() + "</p>")
+				r.cr()
+				backrefs.Reset()
+				continue
+			}
+			if err := r.node(ctx, child, fn.def); err != nil {
+				return err
+			}
+		}
+		if backrefs.Len() > 0 {
+			r.cr()
+			r.out(strings.TrimPrefix(backrefs.String(), " "))
+		}
+		r.cr()
+		r.out("</li>\n")
+	}
+	r.out("</ol>\n</section>")
+	r.cr()
+	return nil
+}
+
+var (
+	safeLinkProtocols  = []string{"http", "https", "mailto", "irc", "ircs", "xmpp"}
+	safeImageProtocols = []string{"http", "https"}
+)
+
+// sanitizeURL 对 URL 做百分号编码，协议不在 protocols 中的绝对 URL 返回空字符串
+func sanitizeURL(url string, protocols []string) string {
+	colon := strings.IndexByte(url, ':')
+	if colon >= 0 && !strings.ContainsAny(url[:colon], "/?#") {
+		protocol := strings.ToLower(url[:colon])
+		safe := false
+		for _, p := range protocols {
+			if protocol == p {
+				safe = true
+				break
+			}
+		}
+		if !safe {
+			return ""
+		}
+	}
+	return normalizeURL(url)
+}
+
+// normalizeURL 对 URL 进行百分号编码，已有的合法编码保持不变
+func normalizeURL(s string) string {
+	const keep = ";/?:@&=+$,-_.!~*'()#"
+	const hex = "0123456789ABCDEF"
+	isHex := func(c byte) bool {
+		return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			sb.WriteByte(c)
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.IndexByte(keep, c) >= 0:
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hex[c>>4])
+			sb.WriteByte(hex[c&15])
+		}
+	}
+	return sb.String()
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// escapeHTML 转义文本和属性值中的 HTML 特殊字符
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
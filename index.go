@@ -0,0 +1,109 @@
+package mdast
+
+// Index 记录语法树中的定义与引用，键为按 CommonMark 规则规范化后的标识符
+type Index struct {
+	// Definitions 是链接定义，同一标识符以第一个定义为准
+	Definitions map[string]*Node
+	// FootnoteDefinitions 是脚注定义，同一标识符以第一个定义为准
+	FootnoteDefinitions map[string]*Node
+	// References 是 linkReference 和 imageReference 节点，按文档顺序排列
+	References map[string][]*Node
+	// FootnoteReferences 是 footnoteReference 节点，按文档顺序排列
+	FootnoteReferences map[string][]*Node
+
+	// Unresolved 是找不到对应定义的引用，按文档顺序排列
+	Unresolved []*Node
+	// Unused 是没有被任何引用使用的定义（含脚注定义），按文档顺序排列
+	Unused []*Node
+	// Duplicates 是与之前的定义标识符重复、因而被忽略的定义，按文档顺序排列
+	Duplicates []*Node
+}
+
+// BuildIndex 遍历 root，建立标识符到定义的映射，并找出未解析的引用、未使用的定义和重复定义
+func BuildIndex(root *Node) *Index {
+	idx := &Index{
+		Definitions:         map[string]*Node{},
+		FootnoteDefinitions: map[string]*Node{},
+		References:          map[string][]*Node{},
+		FootnoteReferences:  map[string][]*Node{},
+	}
+	var defs, refs []*Node
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		id := nodeIdentifier(n)
+		switch n.Type {
+		case NodeDefinition:
+			if _, ok := idx.Definitions[id]; ok {
+				idx.Duplicates = append(idx.Duplicates, n)
+				return Continue
+			}
+			idx.Definitions[id] = n
+			defs = append(defs, n)
+		case NodeFootnoteDefinition:
+			if _, ok := idx.FootnoteDefinitions[id]; ok {
+				idx.Duplicates = append(idx.Duplicates, n)
+				return Continue
+			}
+			idx.FootnoteDefinitions[id] = n
+			defs = append(defs, n)
+		case NodeLinkReference, NodeImageReference:
+			idx.References[id] = append(idx.References[id], n)
+			refs = append(refs, n)
+		case NodeFootnoteReference:
+			idx.FootnoteReferences[id] = append(idx.FootnoteReferences[id], n)
+			refs = append(refs, n)
+		}
+		return Continue
+	}, NodeDefinition, NodeFootnoteDefinition, NodeLinkReference, NodeImageReference, NodeFootnoteReference)
+
+	// 定义可以出现在引用之后，因此在遍历结束后再判断
+	for _, ref := range refs {
+		if _, ok := idx.Resolve(ref); !ok {
+			idx.Unresolved = append(idx.Unresolved, ref)
+		}
+	}
+	for _, def := range defs {
+		id := nodeIdentifier(def)
+		used := len(idx.References[id]) > 0
+		if def.Type == NodeFootnoteDefinition {
+			used = len(idx.FootnoteReferences[id]) > 0
+		}
+		if !used {
+			idx.Unused = append(idx.Unused, def)
+		}
+	}
+	return idx
+}
+
+// Definition 返回标识符对应的链接定义，label 会先被规范化
+func (idx *Index) Definition(label string) (*Node, bool) {
+	def, ok := idx.Definitions[normalizeLabel(label)]
+	return def, ok
+}
+
+// FootnoteDefinition 返回标识符对应的脚注定义，label 会先被规范化
+func (idx *Index) FootnoteDefinition(label string) (*Node, bool) {
+	def, ok := idx.FootnoteDefinitions[normalizeLabel(label)]
+	return def, ok
+}
+
+// Resolve 返回引用节点对应的定义，ref 不是引用节点或找不到定义时返回 false
+func (idx *Index) Resolve(ref *Node) (*Node, bool) {
+	switch ref.Type {
+	case NodeLinkReference, NodeImageReference:
+		def, ok := idx.Definitions[nodeIdentifier(ref)]
+		return def, ok
+	case NodeFootnoteReference:
+		def, ok := idx.FootnoteDefinitions[nodeIdentifier(ref)]
+		return def, ok
+	}
+	return nil, false
+}
+
+// nodeIdentifier 返回节点规范化后的标识符，没有 identifier 时使用 label
+func nodeIdentifier(n *Node) string {
+	id, ok := n.Data.GetString(NDK_Identifier)
+	if !ok || id == "" {
+		id, _ = n.Data.GetString(NDK_Label)
+	}
+	return normalizeLabel(id)
+}
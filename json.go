@@ -0,0 +1,229 @@
+package mdast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonKind 表示节点属性在 JSON 中的值类型
+type jsonKind int
+
+const (
+	jsonString jsonKind = iota
+	jsonInt
+	jsonBool
+	jsonAlign
+	jsonReferenceType
+)
+
+// jsonAttr 描述节点的一个规范属性，nullable 的属性缺失时按 remark 的习惯输出 null
+type jsonAttr struct {
+	key      DataKey
+	kind     jsonKind
+	nullable bool
+}
+
+// jsonAttrs 按 remark 的输出顺序列出各类节点的规范属性
+var jsonAttrs = map[NodeType][]jsonAttr{
+	NodeHeading:            {{key: NDK_Depth, kind: jsonInt}},
+	NodeList:               {{key: NDK_Ordered, kind: jsonBool}, {key: NDK_Start, kind: jsonInt, nullable: true}, {key: NDK_Spread, kind: jsonBool}},
+	NodeListItem:           {{key: NDK_Spread, kind: jsonBool}, {key: NDK_Checked, kind: jsonBool, nullable: true}},
+	NodeCode:               {{key: NDK_Lang, nullable: true}, {key: NDK_Meta, nullable: true}},
+	NodeDefinition:         {{key: NDK_Identifier}, {key: NDK_Label}, {key: NDK_Title, nullable: true}, {key: NDK_URL}},
+	NodeLink:               {{key: NDK_Title, nullable: true}, {key: NDK_URL}},
+	NodeImage:              {{key: NDK_Title, nullable: true}, {key: NDK_URL}, {key: NDK_Alt}},
+	NodeLinkReference:      {{key: NDK_Identifier}, {key: NDK_Label}, {key: NDK_ReferenceType, kind: jsonReferenceType}},
+	NodeImageReference:     {{key: NDK_Alt}, {key: NDK_Identifier}, {key: NDK_Label}, {key: NDK_ReferenceType, kind: jsonReferenceType}},
+	NodeFootnoteReference:  {{key: NDK_Identifier}, {key: NDK_Label}},
+	NodeFootnoteDefinition: {{key: NDK_Identifier}, {key: NDK_Label}},
+	NodeTable:              {{key: NDK_Align, kind: jsonAlign}},
+}
+
+// isLiteralType 判断节点是否以 value 字段承载内容
+func isLiteralType(t NodeType) bool {
+	switch t {
+	case NodeText, NodeInlineCode, NodeCode, NodeHTML, NodeYaml:
+		return true
+	default:
+		return false
+	}
+}
+
+// isParentType 判断节点在 JSON 中是否带有 children 字段
+func isParentType(t NodeType) bool {
+	switch t {
+	case NodeText, NodeInlineCode, NodeCode, NodeHTML, NodeYaml, NodeBreak, NodeThematicBreak,
+		NodeImage, NodeImageReference, NodeDefinition, NodeFootnoteReference:
+		return false
+	default:
+		return true
+	}
+}
+
+// jsonField 是有序输出的一个 JSON 字段
+type jsonField struct {
+	key   string
+	value any
+}
+
+// MarshalJSON 按 remark 的 mdast JSON 结构编码节点：属性位于顶层，子节点统一放在 children 中，
+// 规范属性之外的 Data 放在 data 字段中
+func (n *Node) MarshalJSON() ([]byte, error) {
+	fields := []jsonField{{"type", n.Type}}
+	known := map[DataKey]bool{}
+	for _, attr := range jsonAttrs[n.Type] {
+		known[attr.key] = true
+		value, ok := n.Data[attr.key]
+		switch {
+		case ok && attr.kind == jsonAlign:
+			value = alignToJSON(value)
+		case !ok && attr.nullable:
+			value = nil
+		case !ok:
+			continue
+		}
+		fields = append(fields, jsonField{string(attr.key), value})
+	}
+	if isParentType(n.Type) {
+		fields = append(fields, jsonField{"children", n.Children()})
+	}
+	if isLiteralType(n.Type) {
+		fields = append(fields, jsonField{"value", n.Value})
+	}
+	data := map[string]any{}
+	for key, value := range n.Data {
+		if !known[key] {
+			data[string(key)] = value
+		}
+	}
+	if len(data) > 0 {
+		fields = append(fields, jsonField{"data", data})
+	}
+	if n.Position != nil {
+		fields = append(fields, jsonField{"position", n.Position})
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(f.key); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := enc.Encode(f.value); err != nil {
+			return nil, fmt.Errorf("error encoding %s for %s: %w", f.key, n.Type, err)
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON 解码 remark 结构的 mdast JSON，子节点按父节点类型放入对应的子节点切片
+func (n *Node) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	var nodeType NodeType
+	if err := json.Unmarshal(raw["type"], &nodeType); err != nil || nodeType == "" {
+		return fmt.Errorf("missing or invalid type for node")
+	}
+	*n = *NewNode(nodeType)
+
+	for _, attr := range jsonAttrs[nodeType] {
+		msg, ok := raw[string(attr.key)]
+		if !ok || string(msg) == "null" {
+			continue
+		}
+		value, err := attrFromJSON(attr.kind, msg)
+		if err != nil {
+			return fmt.Errorf("missing or invalid %s for %s: %w", attr.key, nodeType, err)
+		}
+		n.SetData(attr.key, value)
+	}
+	if msg, ok := raw["value"]; ok {
+		if err := json.Unmarshal(msg, &n.Value); err != nil {
+			return fmt.Errorf("missing or invalid value for %s: %w", nodeType, err)
+		}
+	}
+	if msg, ok := raw["data"]; ok && string(msg) != "null" {
+		var data map[string]any
+		if err := json.Unmarshal(msg, &data); err != nil {
+			return fmt.Errorf("missing or invalid data for %s: %w", nodeType, err)
+		}
+		for key, value := range data {
+			n.SetData(DataKey(key), value)
+		}
+	}
+	if msg, ok := raw["position"]; ok && string(msg) != "null" {
+		n.Position = &Position{}
+		if err := json.Unmarshal(msg, n.Position); err != nil {
+			return fmt.Errorf("missing or invalid position for %s: %w", nodeType, err)
+		}
+	}
+	if msg, ok := raw["children"]; ok && string(msg) != "null" {
+		var children []*Node
+		if err := json.Unmarshal(msg, &children); err != nil {
+			return err
+		}
+		for _, child := range children {
+			n.addChild(child)
+		}
+	}
+	return nil
+}
+
+func alignToJSON(value any) any {
+	aligns, ok := value.([]AlignType)
+	if !ok {
+		return value
+	}
+	result := make([]any, len(aligns))
+	for i, a := range aligns {
+		if a != AlignNone {
+			result[i] = string(a)
+		}
+	}
+	return result
+}
+
+func attrFromJSON(kind jsonKind, msg json.RawMessage) (any, error) {
+	switch kind {
+	case jsonInt:
+		var v int
+		err := json.Unmarshal(msg, &v)
+		return v, err
+	case jsonBool:
+		var v bool
+		err := json.Unmarshal(msg, &v)
+		return v, err
+	case jsonAlign:
+		var v []*string
+		if err := json.Unmarshal(msg, &v); err != nil {
+			return nil, err
+		}
+		aligns := make([]AlignType, len(v))
+		for i, a := range v {
+			if a != nil {
+				aligns[i] = AlignType(*a)
+			}
+		}
+		return aligns, nil
+	case jsonReferenceType:
+		var v ReferenceType
+		err := json.Unmarshal(msg, &v)
+		return v, err
+	default:
+		var v string
+		err := json.Unmarshal(msg, &v)
+		return v, err
+	}
+}
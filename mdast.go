@@ -66,6 +66,39 @@ func (n *Node) AddTableChild(child TableContent) {
 	}
 }
 
+// Children 按 Flow、Phrasing、List、Table 的顺序返回节点的全部子节点，不区分子节点所在的切片。
+// 返回的是新切片，修改它不会影响节点
+func (n *Node) Children() []*Node {
+	result := []*Node{}
+	for _, c := range n.FlowChildren {
+		result = append(result, c.(*Node))
+	}
+	for _, c := range n.PhrasingChildren {
+		result = append(result, c.(*Node))
+	}
+	for _, c := range n.ListChildren {
+		result = append(result, c.(*Node))
+	}
+	for _, c := range n.TableChildren {
+		result = append(result, c.(*Node))
+	}
+	return result
+}
+
+// addChild 根据父节点类型将子节点放入对应的子节点切片
+func (n *Node) addChild(child *Node) {
+	switch slotFor(n.Type) {
+	case slotList:
+		n.AddListChild(child)
+	case slotTable:
+		n.AddTableChild(child)
+	case slotPhrasing:
+		n.AddPhrasingChild(child)
+	default:
+		n.AddFlowChild(child)
+	}
+}
+
 // SetData 设置节点数据
 func (n *Node) SetData(key DataKey, value any) {
 	n.Data[key] = value
@@ -86,46 +119,72 @@ func (n *Node) GetType() NodeType {
 func (n *Node) ToMarkdown(ctx context.Context) (string, error) {
 	switch n.Type {
 	case NodeRoot:
-		return flowChildrenToMarkdown(ctx, n)
+		return containerToMarkdown(ctx, n)
 	case NodeParagraph, NodeHeading, NodeBlockquote, NodeCode, NodeThematicBreak,
 		NodeHTML, NodeYaml, NodeDefinition, NodeFootnoteDefinition:
 		return FlowToMarkdown(ctx, n)
+	case NodeList, NodeTable, NodeTableRow, NodeTableCell:
+		s, err := listOrTableToMarkdown(ctx, n)
+		if err != nil {
+			return "", withPosition(n, err)
+		}
+		return s, nil
+	case NodeText, NodeEmphasis, NodeStrong, NodeDelete, NodeLink,
+		NodeImage, NodeInlineCode, NodeBreak,
+		NodeLinkReference, NodeImageReference,
+		NodeFootnoteReference, NodeFootnote:
+		return InlineToMarkdown(ctx, n)
+	default:
+		return "", fmt.Errorf("unknown node type: %s", n.Type)
+	}
+}
+
+// listOrTableToMarkdown 转换列表、表格及其行和单元格，返回的错误由 ToMarkdown 加上位置
+func listOrTableToMarkdown(ctx context.Context, n *Node) (string, error) {
+	switch n.Type {
 	case NodeList:
 		return ListToMarkdown(ctx, n)
 	case NodeTable:
 		return TableToMarkdown(ctx, n)
 	case NodeTableRow:
 		return TableRowToMarkdown(ctx, n)
-	case NodeTableCell:
-		return TableCellToMarkdown(ctx, n)
-	case NodeText, NodeEmphasis, NodeStrong, NodeDelete, NodeLink,
-		NodeImage, NodeInlineCode, NodeBreak,
-		NodeLinkReference, NodeImageReference,
-		NodeFootnoteReference:
-		return InlineToMarkdown(ctx, n)
 	default:
-		return "", fmt.Errorf("unknown node type: %s", n.Type)
+		return TableCellToMarkdown(ctx, n)
 	}
 }
 
-// phrasingChildrenToMarkdown 将子节点转换为 Markdown 文本
+// phrasingChildrenToMarkdown 将子节点转换为 Markdown 文本，段落内容的开头是行首
 func phrasingChildrenToMarkdown(ctx context.Context, n *Node) (string, error) {
-	var result strings.Builder
-	for _, child := range n.PhrasingChildren {
-		childContent, err := InlineToMarkdown(ctx, child.(*Node))
-		if err != nil {
-			return "", err
-		}
-		result.WriteString(childContent)
-	}
-	return result.String(), nil
+	return phrasingToMarkdown(ctx, n, n.Type == NodeParagraph)
 }
 
-// flowChildrenToMarkdown 将流式子节点转换为 Markdown 文本
-func flowChildrenToMarkdown(ctx context.Context, n *Node) (string, error) {
+// phrasingToMarkdown 将子节点转换为 Markdown 文本，lineStart 表示内容的开头位于行首。
+// 相邻的文本节点合并后一起转义，是否位于行首由已输出的内容判断，如 "1" 与 ". x" 合起来才是列表标记
+func phrasingToMarkdown(ctx context.Context, n *Node, lineStart bool) (string, error) {
 	var result strings.Builder
-	for _, child := range n.FlowChildren {
-		childContent, err := FlowToMarkdown(ctx, child.(*Node))
+	children := n.PhrasingChildren
+	for i := 0; i < len(children); i++ {
+		child := children[i].(*Node)
+		if child.Type == NodeText {
+			var text strings.Builder
+			for ; i < len(children) && children[i].GetType() == NodeText; i++ {
+				text.WriteString(children[i].(*Node).Value)
+			}
+			i--
+			// 文本的转义取决于它在行中的位置和后一个兄弟节点
+			tc := textContext{
+				atBreak: (result.Len() == 0 && lineStart) || strings.HasSuffix(result.String(), "\n"),
+			}
+			if i+1 < len(children) {
+				switch children[i+1].GetType() {
+				case NodeLink, NodeLinkReference, NodeFootnoteReference, NodeFootnote:
+					tc.beforeLink = true
+				}
+			}
+			result.WriteString(escapeText(text.String(), tc))
+			continue
+		}
+		childContent, err := InlineToMarkdown(ctx, child)
 		if err != nil {
 			return "", err
 		}
@@ -0,0 +1,130 @@
+package mdast
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructors(t *testing.T) {
+	root := NewRoot(
+		NewHeading(2, NewText("Title")),
+		NewParagraph(
+			NewText("See "),
+			NewLink("https://go.dev", "Go", NewStrong(NewText("Go"))),
+			NewText(" and "),
+			NewLinkReference("Docs", ReferenceCollapsed, NewText("Docs")),
+			NewFootnoteReference("1"),
+		),
+		NewList(true,
+			NewListItem(NewParagraph(NewText("one"))),
+			NewTaskListItem(true, NewParagraph(NewInlineCode("two"))),
+		),
+		NewCode("go", "title=main.go", "package main"),
+		NewTable([]AlignType{AlignLeft, AlignNone},
+			NewTableRow(NewTableCell(NewText("a")), NewTableCell(NewText("b"))),
+		),
+		NewDefinition("Docs", "/docs", ""),
+		NewFootnoteDefinition("1", NewParagraph(NewEmphasis(NewText("note")))),
+	)
+
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "## Title\n\n"+
+		"See [**Go**](https://go.dev \"Go\") and [Docs][][^1]\n\n"+
+		"1. one\n2. [x] `two`\n\n"+
+		"```go title=main.go\npackage main\n```\n\n"+
+		"| a | b |\n| :--- | --- |\n\n"+
+		"[Docs]: /docs\n"+
+		"[^1]: *note*\n\n", md)
+
+	ref := root.FlowChildren[1].(*Node).PhrasingChildren[3].(*Node)
+	assert.Equal(t, "docs", ref.Identifier())
+	assert.Equal(t, "Docs", ref.Label())
+	code := root.FlowChildren[3].(*Node)
+	assert.Equal(t, "go", code.Lang())
+	assert.Equal(t, "title=main.go", code.Meta())
+}
+
+func TestTypedAccessors(t *testing.T) {
+	heading := NewHeading(1)
+	assert.Equal(t, 1, heading.Depth())
+	assert.EqualError(t, heading.SetDepth(7), "invalid heading depth 7, expected 1 to 6")
+	assert.Equal(t, 1, heading.Depth(), "invalid value is not stored")
+	assert.EqualError(t, heading.SetURL("/x"), "url is not a property of heading")
+
+	list := NewList(false)
+	_, ok := list.Start()
+	assert.False(t, ok)
+	require.NoError(t, list.SetStart(3))
+	start, ok := list.Start()
+	assert.True(t, ok)
+	assert.Equal(t, 3, start)
+	assert.Error(t, list.SetStart(-1))
+
+	item := NewListItem()
+	_, ok = item.Checked()
+	assert.False(t, ok)
+	require.NoError(t, item.SetChecked(false))
+	checked, ok := item.Checked()
+	assert.True(t, ok)
+	assert.False(t, checked)
+
+	code := NewCode("", "", "x")
+	assert.Error(t, code.SetLang("go lang"))
+	assert.Error(t, code.SetMeta("a\nb"))
+
+	ref := NewImageReference("Logo", ReferenceFull, "alt")
+	assert.Error(t, ref.SetReferenceType("other"))
+	assert.Error(t, ref.SetLabel(" \n"))
+	assert.Equal(t, ReferenceFull, ref.ReferenceType())
+
+	table := NewTable(nil)
+	assert.Error(t, table.SetAlign([]AlignType{"middle"}))
+
+	// 构造函数不检查取值，不符合规范的值由 Validate 报告
+	var got []string
+	for _, err := range Validate(NewRoot(NewHeading(0), NewCode("go lang", "a\nb", ""), NewDefinition(" ", "/u", ""))) {
+		got = append(got, err.Error())
+	}
+	assert.Equal(t, []string{
+		"root > heading[0]: invalid depth 0 for heading, expected 1 to 6",
+		`root > code[1]: invalid lang "go lang" for code, expected no whitespace`,
+		"root > code[1]: invalid meta for code, expected a single line",
+		"root > definition[2]: missing or invalid identifier for definition",
+	}, got)
+}
+
+func TestLooseDataTypes(t *testing.T) {
+	// 通过 SetData 写入的其他整数类型和字符串也能被读取
+	heading := &Node{Type: NodeHeading, Data: DataTable{NDK_Depth: int64(2)}}
+	heading.AddPhrasingChild(NewText("x"))
+	md, err := heading.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "## x\n\n", md)
+
+	ref := &Node{Type: NodeLinkReference, Data: DataTable{NDK_Identifier: "a", NDK_ReferenceType: "full"}}
+	ref.AddPhrasingChild(NewText("x"))
+	md, err = ref.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "[x][a]", md)
+
+	table := &Node{Type: NodeTable, Data: DataTable{NDK_Align: []string{"right"}}}
+	assert.Equal(t, []AlignType{AlignRight}, table.Align())
+	list := &Node{Type: NodeList, Data: DataTable{NDK_Start: float64(4)}}
+	start, ok := list.Start()
+	assert.True(t, ok)
+	assert.Equal(t, 4, start)
+
+	// 不能精确转换为 int 的值不被接受
+	for _, value := range []any{float64(4.5), math.Inf(1), math.NaN(), float64(1 << 63), uint64(math.MaxUint64), uint(math.MaxUint)} {
+		_, ok := DataTable{NDK_Start: value}.GetInt(NDK_Start)
+		assert.False(t, ok, "%T %v", value, value)
+	}
+	n, ok := DataTable{NDK_Start: float64(-1 << 53)}.GetInt(NDK_Start)
+	assert.True(t, ok)
+	assert.Equal(t, -1<<53, n)
+}
@@ -0,0 +1,90 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	src := "# Title\n\n| a | b |\n| :- | -: |\n| 1 | 2 |\n\n- [x] done\n  - nested [link](/x \"t\")\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+	root.FlowChildren[0].(*Node).SetData("custom", map[string]any{"tags": []string{"a"}})
+
+	clone := root.Clone()
+	eq, diff := Equal(root, clone, EqualOptions{})
+	assert.True(t, eq, diff)
+	assert.Nil(t, clone.Parent())
+	for _, child := range clone.Children() {
+		assert.Same(t, clone, child.Parent())
+	}
+
+	// 修改拷贝不影响原树
+	table := clone.FlowChildren[1].(*Node)
+	table.Align()[0] = AlignCenter
+	clone.FlowChildren[0].(*Node).Data["custom"].(map[string]any)["tags"].([]string)[0] = "changed"
+	clone.FlowChildren[0].(*Node).PhrasingChildren[0].(*Node).Value = "Other"
+	clone.Position.Start.Line = 9
+
+	assert.Equal(t, AlignLeft, root.FlowChildren[1].(*Node).Align()[0])
+	assert.Equal(t, "a", root.FlowChildren[0].(*Node).Data["custom"].(map[string]any)["tags"].([]string)[0])
+	assert.Equal(t, "Title", root.FlowChildren[0].(*Node).PhrasingChildren[0].(*Node).Value)
+	assert.Equal(t, 1, root.Position.Start.Line)
+
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	cloneMD, err := root.Clone().ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, md, cloneMD)
+
+	// Data 中类型为 *Node 的 nil 值不会导致 panic
+	var none *Node
+	n := NewText("x")
+	n.SetData("ref", none)
+	n.SetData("refs", []*Node{nil, NewText("y")})
+	c := n.Clone()
+	assert.Nil(t, c.Data["ref"].(*Node))
+	assert.Nil(t, c.Data["refs"].([]*Node)[0])
+	assert.Equal(t, "y", c.Data["refs"].([]*Node)[1].Value)
+}
+
+func TestEqual(t *testing.T) {
+	parse := func(src string) *Node {
+		root, err := Parse(context.Background(), []byte(src), WithGFM())
+		require.NoError(t, err)
+		return root
+	}
+
+	eq, diff := Equal(parse("a *b*\n"), parse("a *c*\n"), EqualOptions{})
+	assert.False(t, eq)
+	assert.Equal(t, `root > paragraph[0] > emphasis[1] > text[0]: value "b" != "c"`, diff)
+
+	// 位置不同
+	a, b := parse("# x\n"), parse("\n# x\n")
+	eq, diff = Equal(a, b, EqualOptions{})
+	assert.False(t, eq)
+	assert.Contains(t, diff, "root: position")
+	eq, _ = Equal(a, b, EqualOptions{IgnorePosition: true})
+	assert.True(t, eq)
+
+	// 结构相同但父节点指针缺失
+	built := &Node{Type: NodeRoot, FlowChildren: []FlowContent{
+		&Node{Type: NodeHeading, Data: DataTable{NDK_Depth: int64(1)}, PhrasingChildren: []PhrasingContent{&Node{Type: NodeText, Value: "x"}}},
+	}}
+	eq, diff = Equal(a, built, EqualOptions{IgnorePosition: true})
+	assert.False(t, eq)
+	assert.Equal(t, "root > heading[0]: parent set true != false", diff)
+	eq, diff = Equal(a, built, EqualOptions{IgnorePosition: true, IgnoreParent: true})
+	assert.True(t, eq, diff)
+
+	eq, diff = Equal(parse("- a\n"), parse("1. a\n"), EqualOptions{IgnorePosition: true})
+	assert.False(t, eq)
+	assert.Equal(t, "root > list[0]: data ordered false != true", diff)
+
+	eq, diff = Equal(parse("a\n"), parse("a\n\nb\n"), EqualOptions{IgnorePosition: true})
+	assert.False(t, eq)
+	assert.Equal(t, "root: 1 FlowChildren != 2", diff)
+}
@@ -0,0 +1,104 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeFenceLength(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lang     string
+		value    string
+		opts     SerializeOptions
+		expected string
+	}{
+		{"Plain", "go", "x := 1", SerializeOptions{}, "```go\nx := 1\n```\n\n"},
+		{"Contains fence", "md", "```go\nx\n```", SerializeOptions{}, "````md\n```go\nx\n```\n````\n\n"},
+		{"Contains long fence", "", "`````", SerializeOptions{}, "``````\n`````\n``````\n\n"},
+		{"Tilde fence", "md", "~~~~\nx", SerializeOptions{Fence: "~"}, "~~~~~md\n~~~~\nx\n~~~~~\n\n"},
+		{"Backtick in info", "a`b", "x", SerializeOptions{}, "~~~a`b\nx\n~~~\n\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := NewNode(NodeRoot)
+			root.AddFlowChild(createCodeNode(tc.lang, tc.value))
+			md, err := ToMarkdownWithOptions(context.Background(), root, tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, md)
+
+			parsed, err := Parse(context.Background(), []byte(md))
+			require.NoError(t, err)
+			code := parsed.FlowChildren[0].(*Node)
+			require.Equal(t, NodeCode, code.Type)
+			assert.Equal(t, tc.value, code.Value)
+			lang, _ := code.Data.GetString(NDK_Lang)
+			assert.Equal(t, tc.lang, lang)
+		})
+	}
+}
+
+func TestIndentedCode(t *testing.T) {
+	opts := SerializeOptions{IndentedCode: true}
+
+	md, err := ToMarkdownWithOptions(context.Background(), createCodeNode("", "a\n\nb"), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "    a\n\n    b\n\n", md)
+
+	// 有语言或首尾是空白行时仍使用围栏
+	md, err = ToMarkdownWithOptions(context.Background(), createCodeNode("go", "a"), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "```go\na\n```\n\n", md)
+	md, err = ToMarkdownWithOptions(context.Background(), createCodeNode("", "\na"), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "```\n\na\n```\n\n", md)
+
+	root := NewNode(NodeRoot)
+	root.AddFlowChild(createParagraphNode("p"))
+	root.AddFlowChild(createCodeNode("", "  x\ny"))
+	md, err = ToMarkdownWithOptions(context.Background(), root, opts)
+	require.NoError(t, err)
+	parsed, err := Parse(context.Background(), []byte(md))
+	require.NoError(t, err)
+	assert.Equal(t, "  x\ny", parsed.FlowChildren[1].(*Node).Value)
+
+	// 前一个兄弟会吸收缩进的行时改用围栏
+	for _, src := range []string{"- a\n\n```\nb\n```\n", "    a\n\n```\nb\n```\n", "[^a]: x\n\n```\nb\n```\n"} {
+		root, err := Parse(context.Background(), []byte(src), WithGFM())
+		require.NoError(t, err)
+		md, err := ToMarkdownWithOptions(context.Background(), root, opts)
+		require.NoError(t, err)
+		parsed, err := Parse(context.Background(), []byte(md), WithGFM())
+		require.NoError(t, err)
+		ok, diff := Equal(root, parsed, EqualOptions{IgnorePosition: true, IgnoreParent: true})
+		assert.True(t, ok, "markdown: %q, %s", md, diff)
+	}
+}
+
+func TestInlineCodeDelimiters(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{"code", "`code`"},
+		{"a`b", "``a`b``"},
+		{"a``b`c", "```a``b`c```"},
+		{"`tick", "`` `tick ``"},
+		{"tick`", "`` tick` ``"},
+		{" both ", "`  both  `"},
+		{" ", "` `"},
+		{" lead", "` lead`"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			md, parsed := reparse(t, paragraphRoot(&Node{Type: NodeInlineCode, Value: tc.value}))
+			assert.Equal(t, tc.expected+"\n\n", md)
+			code := parsed.FlowChildren[0].(*Node).PhrasingChildren[0].(*Node)
+			require.Equal(t, NodeInlineCode, code.Type)
+			assert.Equal(t, tc.value, code.Value)
+		})
+	}
+}
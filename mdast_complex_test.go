@@ -176,9 +176,10 @@ func TestListWithMissingOrderedProperty(t *testing.T) {
 	list.AddListChild(item)
 	root.AddFlowChild(list)
 
-	_, err := root.ToMarkdown(context.Background())
-	assert.Error(t, err, "Expected an error due to missing 'ordered' property")
-	assert.Contains(t, err.Error(), "missing required 'ordered' property for list")
+	// ordered 是可选属性，未设置时按无序列表输出
+	result, err := root.ToMarkdown(context.Background())
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, "- Test item\n\n", result)
 }
 
 func TestListWithInvalidChild(t *testing.T) {
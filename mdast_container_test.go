@@ -0,0 +1,73 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerNesting(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+	}{
+		{"Code in bullet", "- item\n\n  ```go\n  x := 1\n\n  y := 2\n  ```\n\n"},
+		{"Multi-line paragraph in item", "1. first line\n   second line\n2. next\n\n"},
+		{"Blockquote in item", "- item\n  > quoted\n  > more\n\n"},
+		{"List in blockquote", "> intro\n>\n> - a\n> - b\n\n"},
+		{"Table in item", "- item\n\n  | a | b |\n  | --- | --- |\n  | 1 | 2 |\n\n"},
+		{"Footnote definition", "x[^1]\n\n[^1]: first\n\n    ```\n    code\n    ```\n\n    second\n\n"},
+		{"Three levels", "- one\n  - two\n    > three\n    >\n    > ```\n    > code\n    > ```\n\n"},
+		{"Ordered in quote in item", "- a\n  > 10. ten\n  >     more\n\n"},
+		{"Empty item", "- a\n-\n- c\n\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root, err := Parse(context.Background(), []byte(tc.src), WithGFM())
+			require.NoError(t, err)
+			md, err := root.ToMarkdown(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.src, md)
+
+			// 再次解析得到相同的结构
+			again, err := Parse(context.Background(), []byte(md), WithGFM())
+			require.NoError(t, err)
+			md2, err := again.ToMarkdown(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, md, md2)
+		})
+	}
+}
+
+func TestFootnoteDefinitionIndent(t *testing.T) {
+	def := NewNode(NodeFootnoteDefinition)
+	def.SetData(NDK_Identifier, "note")
+	def.AddFlowChild(createParagraphNode("first"))
+	def.AddFlowChild(createParagraphNode("second"))
+
+	md, err := def.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "[^note]: first\n\n    second\n\n", md)
+}
+
+func TestHTMLPlacement(t *testing.T) {
+	// 流式内容中的 HTML 无论内容如何都以空行结束，短语内容中的 HTML 原样输出
+	comment := NewHTML("<!-- -->")
+	root := NewRoot(
+		NewList(false, NewListItem(NewParagraph(NewText("foo")))),
+		comment,
+		NewParagraph(NewText("a "), NewHTML("<span>"), NewText("b")),
+	)
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "- foo\n\n<!-- -->\n\na <span>b\n\n", md)
+
+	md, err = comment.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "<!-- -->\n\n", md)
+	md, err = root.FlowChildren[2].(*Node).PhrasingChildren[1].(*Node).ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "<span>", md)
+}
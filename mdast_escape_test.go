@@ -0,0 +1,228 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reparse 序列化 root 后重新解析，返回序列化结果和新的语法树
+func reparse(t *testing.T, root *Node) (string, *Node) {
+	t.Helper()
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	parsed, err := Parse(context.Background(), []byte(md), WithGFM())
+	require.NoError(t, err)
+	return md, parsed
+}
+
+func paragraphRoot(children ...PhrasingContent) *Node {
+	root := NewNode(NodeRoot)
+	para := NewNode(NodeParagraph)
+	for _, child := range children {
+		para.AddPhrasingChild(child)
+	}
+	root.AddFlowChild(para)
+	return root
+}
+
+func textNode(value string) *Node {
+	n := NewNode(NodeText)
+	n.Value = value
+	return n
+}
+
+func TestEscapeTextRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"Emphasis markers", "a *b* _c_ d", `a \*b\* \_c\_ d`},
+		{"Intraword underscore", "snake_case_word", "snake_case_word"},
+		{"Brackets and code", "[x] `y` <z>", "\\[x\\] \\`y\\` \\<z>"},
+		{"Strikethrough", "~~gone~~", `\~\~gone\~\~`},
+		{"ATX heading", "# not a heading", `\# not a heading`},
+		{"Hashtag", "#tag", "#tag"},
+		{"Bullet", "- not a list", `\- not a list`},
+		{"Plus bullet", "+ not a list", `\+ not a list`},
+		{"Ordered", "1. not a list", `1\. not a list`},
+		{"Ordered paren", "2) not a list", `2\) not a list`},
+		{"Year", "2024.", `2024\.`},
+		{"Blockquote", "> not a quote", `\> not a quote`},
+		{"Setext underline", "title\n---", "title\n\\---"},
+		{"Setext equals", "title\n===", "title\n\\==="},
+		{"Later line bullet", "a\n- b", "a\n\\- b"},
+		{"Leading spaces", "    not code", "&#x20;   not code"},
+		{"Trailing spaces", "hard  \nbreak", "hard &#x20;\nbreak"},
+		{"Entity", "&amp; &copy; & x", `\&amp; \&copy; & x`},
+		{"Backslash", `a\*b c\d e\`, `a\\\*b c\d e\\`},
+		{"Blank line", "a\n\nb", "a\n&#10;b"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md, parsed := reparse(t, paragraphRoot(textNode(tc.value)))
+			assert.Equal(t, tc.expected+"\n\n", md)
+			para := parsed.FlowChildren[0].(*Node)
+			require.Equal(t, NodeParagraph, para.Type, "markdown: %q", md)
+			require.Len(t, para.PhrasingChildren, 1)
+			assert.Equal(t, tc.value, para.PhrasingChildren[0].(*Node).Value)
+		})
+	}
+}
+
+func TestEscapeLineStart(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name     string
+		root     *Node
+		opts     SerializeOptions
+		expected string
+	}{
+		// 相邻文本合起来判断行首
+		{"Adjacent text", paragraphRoot(textNode("1"), textNode(". x")), SerializeOptions{}, "1\\. x\n\n"},
+		{"After empty text", paragraphRoot(textNode(""), textNode("- x")), SerializeOptions{}, "\\- x\n\n"},
+		// setext 标题的第一行位于行首
+		{"Setext multiline", NewRoot(NewHeading(1, NewText("- a\nb"))), SerializeOptions{}, "\\- a\nb\n=\n\n"},
+		{"Setext list", NewRoot(NewHeading(1, NewText("- a"))), SerializeOptions{Setext: true}, "\\- a\n====\n\n"},
+		{"Setext quote", NewRoot(NewHeading(2, NewText("> a"))), SerializeOptions{Setext: true}, "\\> a\n----\n\n"},
+		{"ATX", NewRoot(NewHeading(1, NewText("- a"))), SerializeOptions{}, "# - a\n\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md, err := ToMarkdownWithOptions(ctx, tc.root, tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, md)
+			parsed, err := Parse(ctx, []byte(md))
+			require.NoError(t, err)
+			require.Len(t, parsed.FlowChildren, 1)
+			assert.Equal(t, tc.root.FlowChildren[0].GetType(), parsed.FlowChildren[0].GetType())
+			assert.Equal(t, ToString(tc.root, StringOptions{}), ToString(parsed, StringOptions{}))
+		})
+	}
+}
+
+func TestEscapeBeforeLink(t *testing.T) {
+	md, parsed := reparse(t, paragraphRoot(textNode("wow!"), createLinkNode("x", "/x")))
+	assert.Equal(t, "wow\\![x](/x)\n\n", md)
+	para := parsed.FlowChildren[0].(*Node)
+	assert.Equal(t, "wow!", para.PhrasingChildren[0].(*Node).Value)
+	assert.Equal(t, NodeLink, para.PhrasingChildren[1].GetType())
+}
+
+func TestEscapeHeading(t *testing.T) {
+	root := NewNode(NodeRoot)
+	root.AddFlowChild(createHeadingNode(2, "Issue #"))
+	root.AddFlowChild(createHeadingNode(2, "C#"))
+	md, parsed := reparse(t, root)
+	assert.Equal(t, "## Issue \\#\n\n## C#\n\n", md)
+	assert.Equal(t, "Issue #", parsed.FlowChildren[0].(*Node).PhrasingChildren[0].(*Node).Value)
+}
+
+func TestEscapeHeadingLineEndings(t *testing.T) {
+	root := NewNode(NodeRoot)
+	root.AddFlowChild(createHeadingNode(2, "a\nb"))
+	root.AddFlowChild(createHeadingNode(3, "c\nd"))
+	broken := createHeadingNode(4, "e")
+	broken.AddPhrasingChild(NewNode(NodeBreak))
+	broken.AddPhrasingChild(textNode("f"))
+	root.AddFlowChild(broken)
+	md, parsed := reparse(t, root)
+	// 1、2 级标题改用 setext 风格，更低级别的标题中软换行写成字符引用，硬换行改为空格
+	assert.Equal(t, "a\nb\n-\n\n### c&#10;d\n\n#### e f\n\n", md)
+	require.Len(t, parsed.FlowChildren, 3)
+	assert.Equal(t, "a\nb", ToString(parsed.FlowChildren[0].(*Node), StringOptions{}))
+	assert.Equal(t, "c\nd", ToString(parsed.FlowChildren[1].(*Node), StringOptions{}))
+}
+
+func TestEscapeDestinationAndTitle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		url      string
+		title    string
+		expected string
+	}{
+		{"Plain", "https://example.com", "", "[t](https://example.com)"},
+		{"Space", "/my file.md", "", "[t](</my file.md>)"},
+		{"Parens", "/wiki/Go_(language)", "", "[t](</wiki/Go_(language)>)"},
+		{"Angle brackets", "/a<b>", "", `[t](</a\<b\>>)`},
+		{"Empty", "", "", "[t](<>)"},
+		{"Backslash and entity", `/a\*b&amp;`, "", `[t](/a\\*b\&amp;)`},
+		{"Title", "/x", "plain", `[t](/x "plain")`},
+		{"Title with double quote", "/x", `say "hi"`, `[t](/x 'say "hi"')`},
+		{"Title with both quotes", "/x", `it's "x"`, `[t](/x "it's \"x\"")`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := createLinkNode("t", tc.url)
+			if tc.title != "" {
+				link.SetData(NDK_Title, tc.title)
+			}
+			md, parsed := reparse(t, paragraphRoot(link))
+			assert.Equal(t, tc.expected+"\n\n", md)
+			got := parsed.FlowChildren[0].(*Node).PhrasingChildren[0].(*Node)
+			require.Equal(t, NodeLink, got.Type, "markdown: %q", md)
+			url, _ := got.Data.GetString(NDK_URL)
+			title, _ := got.Data.GetString(NDK_Title)
+			assert.Equal(t, tc.url, url)
+			assert.Equal(t, tc.title, title)
+		})
+	}
+}
+
+func TestEscapeEmptyTitle(t *testing.T) {
+	ctx := context.Background()
+	// 显式的空标题保留下来，没有标题时不输出
+	for _, src := range []string{"[x](u \"\")\n\n", "[x](u)\n\n", "[a]: u \"\"\n"} {
+		root, err := Parse(ctx, []byte(src))
+		require.NoError(t, err)
+		md, err := root.ToMarkdown(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, src, md)
+	}
+}
+
+func TestEscapeEdgeWhitespace(t *testing.T) {
+	testCases := []struct {
+		name     string
+		node     *Node
+		expected string
+	}{
+		{"Strong", NewStrong(NewText(" a ")), "**&#x20;a&#x20;**"},
+		{"Emphasis", NewEmphasis(NewText("\ta")), "*&#x9;a*"},
+		{"Only space", NewEmphasis(NewText(" ")), "*&#x20;*"},
+		{"Inner space", NewStrong(NewText("a b")), "**a b**"},
+		{"Delete", NewDelete(NewText("a ")), "~~a&#x20;~~"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := paragraphRoot(tc.node)
+			md, parsed := reparse(t, root)
+			assert.Equal(t, tc.expected+"\n\n", md)
+			ok, diff := Equal(root, parsed, EqualOptions{IgnorePosition: true, IgnoreParent: true})
+			assert.True(t, ok, "markdown: %q, %s", md, diff)
+		})
+	}
+}
+
+func TestEscapeQuoteOption(t *testing.T) {
+	link := createLinkNode("t", "/x")
+	link.SetData(NDK_Title, "title")
+	md, err := ToMarkdownWithOptions(context.Background(), link, SerializeOptions{Quote: "'"})
+	require.NoError(t, err)
+	assert.Equal(t, "[t](/x 'title')", md)
+
+	def := createDefinitionNode("ref", "/a b", `it's`)
+	md, err = ToMarkdownWithOptions(context.Background(), def, SerializeOptions{Quote: "'"})
+	require.NoError(t, err)
+	assert.Equal(t, "[ref]: </a b> \"it's\"\n", md)
+}
+
+func TestEscapeImageAlt(t *testing.T) {
+	md, parsed := reparse(t, paragraphRoot(createImageNode("a [b] *c*", "/i.png")))
+	assert.Equal(t, "![a \\[b\\] \\*c\\*](/i.png)\n\n", md)
+	alt, _ := parsed.FlowChildren[0].(*Node).PhrasingChildren[0].(*Node).Data.GetString(NDK_Alt)
+	assert.Equal(t, "a [b] *c*", alt)
+}
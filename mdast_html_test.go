@@ -0,0 +1,128 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderHTML(t *testing.T, src string, opts ...HTMLOption) string {
+	t.Helper()
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+	out, err := ToHTML(context.Background(), root, opts...)
+	require.NoError(t, err)
+	return out
+}
+
+func TestToHTMLBlocks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "Heading and paragraph",
+			markdown: "## Title\n\nSome *em* and **strong** and ~~del~~ and `code`.  \nNext line.\n",
+			expected: "<h2>Title</h2>\n<p>Some <em>em</em> and <strong>strong</strong> and <del>del</del> and <code>code</code>.<br>\nNext line.</p>\n",
+		},
+		{
+			name:     "Ordered list with start",
+			markdown: "3. three\n4. four\n",
+			expected: "<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n",
+		},
+		{
+			name:     "Loose list",
+			markdown: "- a\n\n- b\n",
+			expected: "<ul>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ul>\n",
+		},
+		{
+			name:     "Task list",
+			markdown: "- [x] done\n- [ ] todo\n",
+			expected: "<ul class=\"contains-task-list\">\n<li class=\"task-list-item\"><input type=\"checkbox\" checked disabled> done</li>\n<li class=\"task-list-item\"><input type=\"checkbox\" disabled> todo</li>\n</ul>\n",
+		},
+		{
+			name:     "Code and rule",
+			markdown: "```go\na < b\n```\n\n***\n\n> quote\n",
+			expected: "<pre><code class=\"language-go\">a &lt; b\n</code></pre>\n<hr>\n<blockquote>\n<p>quote</p>\n</blockquote>\n",
+		},
+		{
+			name:     "Table with align and ragged rows",
+			markdown: "| a | b |\n|:-:|--:|\n| 1 |\n| 2 | 3 | 4 |\n",
+			expected: "<table>\n<thead>\n<tr>\n<th align=\"center\">a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n<tbody>\n" +
+				"<tr>\n<td align=\"center\">1</td>\n<td align=\"right\"></td>\n</tr>\n" +
+				"<tr>\n<td align=\"center\">2</td>\n<td align=\"right\">3</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, renderHTML(t, tc.markdown))
+		})
+	}
+}
+
+func TestToHTMLReferences(t *testing.T) {
+	out := renderHTML(t, "See [docs][] and ![logo][l] and [missing][x].\n\n[docs]: https://d.example \"Docs\"\n[l]: /logo.png\n")
+	assert.Equal(t, "<p>See <a href=\"https://d.example\" title=\"Docs\">docs</a> and <img src=\"/logo.png\" alt=\"logo\"> and [missing][x].</p>\n", out)
+
+	// 手工构建的未解析引用按引用类型还原
+	root := NewNode(NodeParagraph)
+	root.AddPhrasingChild(createLinkReferenceNode("nope", "text", ReferenceCollapsed))
+	out, err := ToHTML(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>[text][]</p>\n", out)
+}
+
+func TestToHTMLFootnotes(t *testing.T) {
+	out := renderHTML(t, "A[^n] and B[^n] and C[^m].\n\n[^m]: Second.\n[^n]: The *note*.\n[^unused]: Never shown.\n")
+	assert.Equal(t, "<p>A<sup><a href=\"#user-content-fn-n\" id=\"user-content-fnref-n\" data-footnote-ref=\"\" aria-describedby=\"footnote-label\">1</a></sup>"+
+		" and B<sup><a href=\"#user-content-fn-n\" id=\"user-content-fnref-n-2\" data-footnote-ref=\"\" aria-describedby=\"footnote-label\">1</a></sup>"+
+		" and C<sup><a href=\"#user-content-fn-m\" id=\"user-content-fnref-m\" data-footnote-ref=\"\" aria-describedby=\"footnote-label\">2</a></sup>.</p>\n"+
+		"<section data-footnotes=\"\" class=\"footnotes\"><h2 class=\"sr-only\" id=\"footnote-label\">Footnotes</h2>\n<ol>\n"+
+		"<li id=\"user-content-fn-n\">\n<p>The <em>note</em>."+
+		" <a href=\"#user-content-fnref-n\" data-footnote-backref=\"\" aria-label=\"Back to reference 1\" class=\"data-footnote-backref\">↩</a>"+
+		" <a href=\"#user-content-fnref-n-2\" data-footnote-backref=\"\" aria-label=\"Back to reference 1-2\" class=\"data-footnote-backref\">↩<sup>2</sup></a></p>\n</li>\n"+
+		"<li id=\"user-content-fn-m\">\n<p>Second."+
+		" <a href=\"#user-content-fnref-m\" data-footnote-backref=\"\" aria-label=\"Back to reference 2\" class=\"data-footnote-backref\">↩</a></p>\n</li>\n"+
+		"</ol>\n</section>\n", out)
+
+	out = renderHTML(t, "A[^1]\n\n[^1]: Note.\n", WithClobberPrefix(""), WithFootnoteLabels("注释", "返回 %s"))
+	assert.Contains(t, out, `<a href="#fn-1" id="fnref-1"`)
+	assert.Contains(t, out, `id="footnote-label">注释</h2>`)
+	assert.Contains(t, out, `aria-label="返回 1"`)
+
+	// 标签中的其他 % 原样输出，没有占位符时不追加序号
+	out = renderHTML(t, "A[^1]\n\n[^1]: Note.\n", WithFootnoteLabels("Notes", "100% back to %s, %d"))
+	assert.Contains(t, out, `aria-label="100% back to 1, %d"`)
+	out = renderHTML(t, "A[^1]\n\n[^1]: Note.\n", WithFootnoteLabels("Notes", "Back"))
+	assert.Contains(t, out, `aria-label="Back"`)
+}
+
+func TestToHTMLSafety(t *testing.T) {
+	out := renderHTML(t, "<script>alert(1)</script>\n\nx <b>y</b> [a](javascript:alert(1)) ![i](data:image/png;base64,AAAA) [b](/path with \"q\") [c](MAILTO:me@example.com)\n")
+	assert.Equal(t, "<p>x y <a href=\"\">a</a> <img src=\"\" alt=\"i\"> [b](/path with &quot;q&quot;) <a href=\"MAILTO:me@example.com\">c</a></p>\n", out)
+
+	out = renderHTML(t, "<div>raw</div>\n\nx <b>y</b>\n", WithDangerousHTML())
+	assert.Equal(t, "<div>raw</div>\n<p>x <b>y</b></p>\n", out)
+
+	link := createLinkNode("t", "/a b\"<")
+	out, err := ToHTML(context.Background(), link)
+	require.NoError(t, err)
+	assert.Equal(t, "<a href=\"/a%20b%22%3C\">t</a>", out)
+}
+
+func TestToHTMLErrors(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("# Title\n"))
+	require.NoError(t, err)
+	root.FlowChildren[0].(*Node).Data[NDK_Depth] = 9
+	_, err = ToHTML(context.Background(), root)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1:1-1:8")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ToHTML(ctx, root)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,80 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const indexSource = `See [Foo  Bar][], ![logo][LOGO], [missing] and [^Note].
+
+Also [foo bar][] and [^gone].
+
+[FOO BAR]: /foo
+[logo]: /logo.png
+[foo   bar]: /duplicate
+[unused]: /unused
+
+[^note]: A note.
+
+[^spare]: Never used.
+`
+
+func TestBuildIndex(t *testing.T) {
+	root, err := Parse(context.Background(), []byte(indexSource), WithGFM())
+	require.NoError(t, err)
+	idx := BuildIndex(root)
+
+	def, ok := idx.Definition("  Foo\n  BAR ")
+	require.True(t, ok)
+	url, _ := def.Data.GetString(NDK_URL)
+	assert.Equal(t, "/foo", url, "first definition wins")
+	assert.Len(t, idx.References["foo bar"], 2)
+	assert.Len(t, idx.References["logo"], 1)
+
+	fn, ok := idx.FootnoteDefinition("NOTE")
+	require.True(t, ok)
+	assert.Equal(t, NodeFootnoteDefinition, fn.Type)
+	assert.Len(t, idx.FootnoteReferences["note"], 1)
+
+	for _, ref := range idx.References["logo"] {
+		resolved, ok := idx.Resolve(ref)
+		require.True(t, ok)
+		assert.Same(t, idx.Definitions["logo"], resolved)
+	}
+
+	// 解析器会把找不到定义的 [missing] 和 [^gone] 保留为文本
+	assert.Empty(t, idx.Unresolved)
+
+	require.Len(t, idx.Unused, 2)
+	assert.Equal(t, "unused", nodeIdentifier(idx.Unused[0]))
+	assert.Equal(t, "spare", nodeIdentifier(idx.Unused[1]))
+
+	require.Len(t, idx.Duplicates, 1)
+	url, _ = idx.Duplicates[0].Data.GetString(NDK_URL)
+	assert.Equal(t, "/duplicate", url)
+}
+
+func TestBuildIndexConstructedTree(t *testing.T) {
+	root := NewNode(NodeRoot)
+	para := NewNode(NodeParagraph)
+	para.AddPhrasingChild(createLinkReferenceNode("Ref", "text", "full"))
+	para.AddPhrasingChild(createImageReferenceNode("nowhere", "alt", "full"))
+	root.AddFlowChild(para)
+	root.AddFlowChild(createDefinitionNode("ref", "/r", ""))
+	root.AddFlowChild(createFootnoteDefinitionNode("a", "one"))
+	root.AddFlowChild(createFootnoteDefinitionNode("A", "two"))
+
+	idx := BuildIndex(root)
+	require.Len(t, idx.Unresolved, 1)
+	assert.Equal(t, NodeImageReference, idx.Unresolved[0].Type)
+	require.Len(t, idx.Unused, 1)
+	assert.Equal(t, NodeFootnoteDefinition, idx.Unused[0].Type)
+	require.Len(t, idx.Duplicates, 1)
+	assert.Same(t, root.FlowChildren[3], idx.Duplicates[0])
+
+	_, ok := idx.Resolve(para)
+	assert.False(t, ok)
+}
@@ -39,17 +39,17 @@ func createNestedInlineNode() *Node {
 func TestInlineElements(t *testing.T) {
 	testCases := []TestCase{
 		{"Text", &Node{Type: NodeText, Value: "Hello"}, "Hello", false},
-		{"Text with special characters", &Node{Type: NodeText, Value: "Hello * _ ` [ ]"}, "Hello * _ ` [ ]", false},
+		{"Text with special characters", &Node{Type: NodeText, Value: "Hello * _ ` [ ]"}, "Hello \\* \\_ \\` \\[ \\]", false},
 		{"Emphasis", &Node{Type: NodeEmphasis, PhrasingChildren: []PhrasingContent{&Node{Type: NodeText, Value: "em"}}}, "*em*", false},
 		{"Strong", &Node{Type: NodeStrong, PhrasingChildren: []PhrasingContent{&Node{Type: NodeText, Value: "strong"}}}, "**strong**", false},
 		{"Delete", &Node{Type: NodeDelete, PhrasingChildren: []PhrasingContent{&Node{Type: NodeText, Value: "deleted"}}}, "~~deleted~~", false},
 		{"InlineCode", &Node{Type: NodeInlineCode, Value: "code"}, "`code`", false},
-		{"InlineCode with backticks", &Node{Type: NodeInlineCode, Value: "code with ` backticks"}, "`code with ` backticks`", false},
+		{"InlineCode with backticks", &Node{Type: NodeInlineCode, Value: "code with ` backticks"}, "``code with ` backticks``", false},
 		{"Link", createLinkNode("Example", "https://example.com"), "[Example](https://example.com)", false},
 		{"Link with title", createLinkNodeWithTitle("Example", "https://example.com", "Title"), "[Example](https://example.com \"Title\")", false},
 		{"Image", createImageNode("Alt text", "https://example.com/image.png"), "![Alt text](https://example.com/image.png)", false},
 		{"Image with title", createImageNodeWithTitle("Alt text", "https://example.com/image.png", "Title"), "![Alt text](https://example.com/image.png \"Title\")", false},
-		{"Break", NewNode(NodeBreak), "\n", false},
+		{"Break", NewNode(NodeBreak), "\\\n", false},
 		{"Nested Inline", createNestedInlineNode(), "This is *emphasized and **strong** text* with `code`", false},
 	}
 
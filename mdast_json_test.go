@@ -0,0 +1,150 @@
+package mdast
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remarkHeadingJSON 是 remark 解析 "## Hi *x*\n" 得到的 JSON
+const remarkHeadingJSON = `{
+  "type": "root",
+  "children": [
+    {
+      "type": "heading",
+      "depth": 2,
+      "children": [
+        {"type": "text", "value": "Hi ", "position": {"start": {"line": 1, "column": 4, "offset": 3}, "end": {"line": 1, "column": 7, "offset": 6}}},
+        {
+          "type": "emphasis",
+          "children": [
+            {"type": "text", "value": "x", "position": {"start": {"line": 1, "column": 8, "offset": 7}, "end": {"line": 1, "column": 9, "offset": 8}}}
+          ],
+          "position": {"start": {"line": 1, "column": 7, "offset": 6}, "end": {"line": 1, "column": 10, "offset": 9}}
+        }
+      ],
+      "position": {"start": {"line": 1, "column": 1, "offset": 0}, "end": {"line": 1, "column": 10, "offset": 9}}
+    }
+  ],
+  "position": {"start": {"line": 1, "column": 1, "offset": 0}, "end": {"line": 2, "column": 1, "offset": 10}}
+}`
+
+func TestMarshalJSONMatchesRemark(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("## Hi *x*\n"))
+	require.NoError(t, err)
+	out, err := json.Marshal(root)
+	require.NoError(t, err)
+	assert.JSONEq(t, remarkHeadingJSON, string(out))
+}
+
+func TestMarshalJSONFields(t *testing.T) {
+	root := NewNode(NodeRoot)
+
+	list := NewNode(NodeList)
+	list.SetData(NDK_Ordered, false)
+	list.SetData(NDK_Spread, false)
+	item := NewNode(NodeListItem)
+	item.SetData(NDK_Spread, false)
+	item.SetData(NDK_Checked, true)
+	para := NewNode(NodeParagraph)
+	link := NewNode(NodeLink)
+	link.SetData(NDK_URL, "https://example.com")
+	text := NewNode(NodeText)
+	text.Value = "a <b>"
+	link.AddPhrasingChild(text)
+	para.AddPhrasingChild(link)
+	item.AddFlowChild(para)
+	list.AddListChild(item)
+	root.AddFlowChild(list)
+
+	code := NewNode(NodeCode)
+	code.Value = "x := 1"
+	root.AddFlowChild(code)
+
+	table := NewNode(NodeTable)
+	table.SetData(NDK_Align, []AlignType{AlignLeft, AlignNone})
+	row := NewNode(NodeTableRow)
+	row.AddTableChild(NewNode(NodeTableCell))
+	table.AddTableChild(row)
+	root.AddFlowChild(table)
+
+	ref := NewNode(NodeImageReference)
+	ref.SetData(NDK_Alt, "logo")
+	ref.SetData(NDK_Identifier, "logo")
+	ref.SetData(NDK_Label, "Logo")
+	ref.SetData(NDK_ReferenceType, ReferenceShortcut)
+	ref.SetData("hName", "img")
+	p2 := NewNode(NodeParagraph)
+	p2.AddPhrasingChild(ref)
+	root.AddFlowChild(p2)
+
+	out, err := json.Marshal(root)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"root","children":[
+		{"type":"list","ordered":false,"start":null,"spread":false,"children":[
+			{"type":"listItem","spread":false,"checked":true,"children":[
+				{"type":"paragraph","children":[
+					{"type":"link","title":null,"url":"https://example.com","children":[{"type":"text","value":"a <b>"}]}
+				]}
+			]}
+		]},
+		{"type":"code","lang":null,"meta":null,"value":"x := 1"},
+		{"type":"table","align":["left",null],"children":[{"type":"tableRow","children":[{"type":"tableCell","children":[]}]}]},
+		{"type":"paragraph","children":[
+			{"type":"imageReference","alt":"logo","identifier":"logo","label":"Logo","referenceType":"shortcut","data":{"hName":"img"}}
+		]}
+	]}`, string(out))
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var root Node
+	require.NoError(t, json.Unmarshal([]byte(remarkHeadingJSON), &root))
+
+	require.Len(t, root.FlowChildren, 1)
+	heading := root.FlowChildren[0].(*Node)
+	depth, ok := heading.Data.GetInt(NDK_Depth)
+	assert.True(t, ok)
+	assert.Equal(t, 2, depth)
+	require.Len(t, heading.PhrasingChildren, 2)
+	assert.Equal(t, &Position{Start: Point{1, 7, 6}, End: Point{1, 10, 9}}, heading.PhrasingChildren[1].(*Node).Position)
+	assert.Same(t, heading, heading.PhrasingChildren[1].(*Node).parent)
+
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "## Hi *x*\n\n", md)
+
+	out, err := json.Marshal(&root)
+	require.NoError(t, err)
+	assert.JSONEq(t, remarkHeadingJSON, string(out))
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	src := "# Title\n\n1. one\n2. [two](/t \"T\")\n\n| a | b |\n|:--|---|\n| `c` | ~~d~~ |\n\n- [ ] todo\n\n```go\ncode\n```\n\n![img][ref] and note[^1]\n\n[ref]: /img.png\n\n[^1]: The note.\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+
+	out, err := json.Marshal(root)
+	require.NoError(t, err)
+	var decoded Node
+	require.NoError(t, json.Unmarshal(out, &decoded))
+
+	want, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	got, err := decoded.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	again, err := json.Marshal(&decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(out), string(again))
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	var n Node
+	assert.Error(t, json.Unmarshal([]byte(`{"children":[]}`), &n))
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"heading","depth":"two"}`), &n))
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"root","children":[{"value":"x"}]}`), &n))
+}
@@ -0,0 +1,87 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listItemNode(text string) *Node {
+	item := NewNode(NodeListItem)
+	item.AddFlowChild(createParagraphNode(text))
+	return item
+}
+
+func TestListStart(t *testing.T) {
+	list := NewNode(NodeList)
+	list.SetData(NDK_Ordered, true)
+	list.SetData(NDK_Start, 9)
+	list.AddListChild(listItemNode("nine"))
+	list.AddListChild(listItemNode("ten"))
+
+	md, err := list.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "9. nine\n10. ten\n\n", md)
+
+	md, err = ToMarkdownWithOptions(context.Background(), list, SerializeOptions{RepeatListMarker: true})
+	require.NoError(t, err)
+	assert.Equal(t, "9. nine\n9. ten\n\n", md)
+
+	list.SetData(NDK_Start, 1)
+	md, err = ToMarkdownWithOptions(context.Background(), list, SerializeOptions{RepeatListMarker: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1. nine\n1. ten\n\n", md)
+
+	// 没有 ordered 但有 start 时视为有序列表
+	delete(list.Data, NDK_Ordered)
+	list.SetData(NDK_Start, 3)
+	md, err = list.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "3. nine\n4. ten\n\n", md)
+	html, err := ToHTML(context.Background(), NewRoot(list))
+	require.NoError(t, err)
+	assert.Contains(t, html, `<ol start="3">`)
+}
+
+func TestListTaskItems(t *testing.T) {
+	list := NewNode(NodeList)
+	list.SetData(NDK_Ordered, false)
+	done := listItemNode("done")
+	done.SetData(NDK_Checked, true)
+	todo := listItemNode("todo")
+	todo.SetData(NDK_Checked, false)
+	list.AddListChild(done)
+	list.AddListChild(todo)
+	list.AddListChild(listItemNode("plain"))
+
+	md, err := list.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "- [x] done\n- [ ] todo\n- plain\n\n", md)
+}
+
+func TestListItemSpread(t *testing.T) {
+	list := NewNode(NodeList)
+	list.SetData(NDK_Ordered, false)
+	list.SetData(NDK_Spread, false)
+	item := NewNode(NodeListItem)
+	item.SetData(NDK_Spread, true)
+	item.AddFlowChild(createParagraphNode("first"))
+	item.AddFlowChild(createParagraphNode("second"))
+	list.AddListChild(item)
+	list.AddListChild(listItemNode("next"))
+
+	md, err := list.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "- first\n\n  second\n- next\n\n", md)
+}
+
+func TestListRoundTrip(t *testing.T) {
+	src := "3. three\n4. four\n\n- [x] done\n- [ ] todo\n\nafter\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "3. three\n4. four\n\n- [x] done\n- [ ] todo\n\nafter\n\n", md)
+}
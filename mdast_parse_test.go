@@ -0,0 +1,533 @@
+package mdast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// specExample 对应 CommonMark 规范或 GFM 示例集中的一个示例
+type specExample struct {
+	Example  int    `json:"example"`
+	Section  string `json:"section"`
+	Markdown string `json:"markdown"`
+	HTML     string `json:"html"`
+}
+
+func loadSpecExamples(t *testing.T, path string) []specExample {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var examples []specExample
+	require.NoError(t, json.Unmarshal(raw, &examples))
+	return examples
+}
+
+func TestParseCommonMarkSpec(t *testing.T) {
+	examples := loadSpecExamples(t, "testdata/commonmark_spec.json")
+	// testdata/commonmark_spec.json 是 CommonMark 0.31.2 的 spec.json 原文，共 652 个示例
+	require.Len(t, examples, 652)
+	for _, ex := range examples {
+		ex := ex
+		t.Run(fmt.Sprintf("%s/%d", ex.Section, ex.Example), func(t *testing.T) {
+			root, err := Parse(context.Background(), []byte(ex.Markdown))
+			require.NoError(t, err)
+			assert.Equal(t, ex.HTML, renderSpecHTML(root), "markdown: %q", ex.Markdown)
+		})
+	}
+}
+
+// TestParseGFMFixture 检查 GFM 扩展的解析结果。testdata/gfm_fixture.json 是按表格、任务列表、删除线、
+// 自动链接和脚注整理的示例，编号只在该文件内有效，与 GFM 规范中的示例编号无关；脚注的 HTML 采用 cmark-gfm 的格式
+func TestParseGFMFixture(t *testing.T) {
+	for _, ex := range loadSpecExamples(t, "testdata/gfm_fixture.json") {
+		ex := ex
+		t.Run(fmt.Sprintf("%s/%d", ex.Section, ex.Example), func(t *testing.T) {
+			root, err := Parse(context.Background(), []byte(ex.Markdown), WithGFM())
+			require.NoError(t, err)
+			assert.Equal(t, ex.HTML, renderSpecHTML(root), "markdown: %q", ex.Markdown)
+		})
+	}
+}
+
+func TestParseGFMDisabled(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("~~a~~ www.example.com\n\n| a |\n| - |\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "<p>~~a~~ www.example.com</p>\n<p>| a |\n| - |</p>\n", renderSpecHTML(root))
+}
+
+func TestParseGFMTree(t *testing.T) {
+	src := "| a | b |\n|:--|--:|\n| 1 |\n\n- [x] done\n- [ ] todo\n\nText[^note] and [^missing].\n\n[^note]: The *note*.\n    More.\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+	require.Len(t, root.FlowChildren, 4)
+
+	table := root.FlowChildren[0].(*Node)
+	assert.Equal(t, NodeTable, table.Type)
+	assert.Equal(t, []AlignType{AlignLeft, AlignRight}, table.Data[NDK_Align])
+	require.Len(t, table.TableChildren, 2)
+	assert.Len(t, table.TableChildren[0].(*Node).TableChildren, 2)
+	// 缺少的单元格保持原样，不做补齐
+	assert.Len(t, table.TableChildren[1].(*Node).TableChildren, 1)
+
+	list := root.FlowChildren[1].(*Node)
+	done, _ := list.ListChildren[0].(*Node).Data.GetBool(NDK_Checked)
+	todo, ok := list.ListChildren[1].(*Node).Data.GetBool(NDK_Checked)
+	assert.True(t, done)
+	assert.True(t, ok)
+	assert.False(t, todo)
+	assert.Equal(t, "done", list.ListChildren[0].(*Node).FlowChildren[0].(*Node).PhrasingChildren[0].(*Node).Value)
+
+	para := root.FlowChildren[2].(*Node)
+	require.Len(t, para.PhrasingChildren, 3)
+	ref := para.PhrasingChildren[1].(*Node)
+	assert.Equal(t, NodeFootnoteReference, ref.Type)
+	id, _ := ref.Data.GetString(NDK_Identifier)
+	assert.Equal(t, "note", id)
+	assert.Equal(t, " and [^missing].", para.PhrasingChildren[2].(*Node).Value)
+
+	def := root.FlowChildren[3].(*Node)
+	assert.Equal(t, NodeFootnoteDefinition, def.Type)
+	label, _ := def.Data.GetString(NDK_Label)
+	assert.Equal(t, "note", label)
+	require.Len(t, def.FlowChildren, 1)
+	assert.Equal(t, "<p>The <em>note</em>.\nMore.</p>\n", renderSpecHTML(def))
+
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, md, "| :--- | ---: |")
+}
+
+func TestParseTree(t *testing.T) {
+	src := "# Title\n\nSome *emphasis* and [a link](https://example.com \"T\").\n\n" +
+		"- one\n- two\n\n3. three\n\n```go\nfmt.Println(1)\n```\n\n> quote\n\n[ref]: /url\n"
+	root, err := Parse(context.Background(), []byte(src))
+	require.NoError(t, err)
+	require.Equal(t, NodeRoot, root.Type)
+	require.Len(t, root.FlowChildren, 7)
+
+	heading := root.FlowChildren[0].(*Node)
+	assert.Equal(t, NodeHeading, heading.Type)
+	depth, _ := heading.Data.GetInt(NDK_Depth)
+	assert.Equal(t, 1, depth)
+	assert.Equal(t, "Title", heading.PhrasingChildren[0].(*Node).Value)
+
+	para := root.FlowChildren[1].(*Node)
+	assert.Equal(t, NodeParagraph, para.Type)
+	require.Len(t, para.PhrasingChildren, 5)
+	assert.Equal(t, NodeEmphasis, para.PhrasingChildren[1].(*Node).Type)
+	link := para.PhrasingChildren[3].(*Node)
+	assert.Equal(t, NodeLink, link.Type)
+	url, _ := link.Data.GetString(NDK_URL)
+	title, _ := link.Data.GetString(NDK_Title)
+	assert.Equal(t, "https://example.com", url)
+	assert.Equal(t, "T", title)
+
+	bullet := root.FlowChildren[2].(*Node)
+	ordered, _ := bullet.Data.GetBool(NDK_Ordered)
+	spread, _ := bullet.Data.GetBool(NDK_Spread)
+	assert.False(t, ordered)
+	assert.False(t, spread)
+	assert.Len(t, bullet.ListChildren, 2)
+	assert.Same(t, bullet, bullet.ListChildren[0].(*Node).parent)
+
+	numbered := root.FlowChildren[3].(*Node)
+	ordered, _ = numbered.Data.GetBool(NDK_Ordered)
+	start, _ := numbered.Data.GetInt(NDK_Start)
+	assert.True(t, ordered)
+	assert.Equal(t, 3, start)
+
+	code := root.FlowChildren[4].(*Node)
+	lang, _ := code.Data.GetString(NDK_Lang)
+	assert.Equal(t, NodeCode, code.Type)
+	assert.Equal(t, "go", lang)
+	assert.Equal(t, "fmt.Println(1)", code.Value)
+
+	assert.Equal(t, NodeBlockquote, root.FlowChildren[5].(*Node).Type)
+
+	def := root.FlowChildren[6].(*Node)
+	id, _ := def.Data.GetString(NDK_Identifier)
+	assert.Equal(t, NodeDefinition, def.Type)
+	assert.Equal(t, "ref", id)
+}
+
+func TestParseReferences(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("[Foo][Bar] ![img][] [bar]\n\n[BAR]: /url \"title\"\n[img]: /i.png\n"))
+	require.NoError(t, err)
+	para := root.FlowChildren[0].(*Node)
+
+	full := para.PhrasingChildren[0].(*Node)
+	assert.Equal(t, NodeLinkReference, full.Type)
+	id, _ := full.Data.GetString(NDK_Identifier)
+	label, _ := full.Data.GetString(NDK_Label)
+	refType, _ := full.Data.GetReferenceType(NDK_ReferenceType)
+	assert.Equal(t, "bar", id)
+	assert.Equal(t, "Bar", label)
+	assert.Equal(t, ReferenceFull, refType)
+
+	img := para.PhrasingChildren[2].(*Node)
+	assert.Equal(t, NodeImageReference, img.Type)
+	alt, _ := img.Data.GetString(NDK_Alt)
+	refType, _ = img.Data.GetReferenceType(NDK_ReferenceType)
+	assert.Equal(t, "img", alt)
+	assert.Equal(t, ReferenceCollapsed, refType)
+
+	shortcut := para.PhrasingChildren[4].(*Node)
+	refType, _ = shortcut.Data.GetReferenceType(NDK_ReferenceType)
+	assert.Equal(t, ReferenceShortcut, refType)
+
+	// 未定义的引用保持为普通文本
+	root, err = Parse(context.Background(), []byte("[missing]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "[missing]", root.FlowChildren[0].(*Node).PhrasingChildren[0].(*Node).Value)
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	src := "# Title\n\nSome **strong** text with `code`.\n\n> quoted\n\n---\n"
+	root, err := Parse(context.Background(), []byte(src))
+	require.NoError(t, err)
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+
+	again, err := Parse(context.Background(), []byte(md))
+	require.NoError(t, err)
+	assert.Equal(t, renderSpecHTML(root), renderSpecHTML(again))
+}
+
+// commonMarkRoundTripSkips 列出 Parse → ToMarkdown → Parse 后得到不同语法树的 CommonMark 示例及原因，键为上游示例编号
+var commonMarkRoundTripSkips = map[int]string{
+	173: "an html block of type 1 without its end condition runs to the end of the document and absorbs the trailing blank line",
+}
+
+// gfmRoundTripSkips 列出往返后语法树不同的 GFM 示例及原因，键为 testdata/gfm_fixture.json 中的编号
+var gfmRoundTripSkips = map[int]string{
+	5: "ragged table rows are normalized to the header width when serialized",
+	7: "ragged table rows are normalized to the header width when serialized",
+}
+
+// TestSpecRoundTrip 检查规范和 GFM 示例解析后输出的 Markdown 能重新解析为相同的语法树
+func TestSpecRoundTrip(t *testing.T) {
+	suites := []struct {
+		name  string
+		path  string
+		opts  []ParseOption
+		skips map[int]string
+	}{
+		{"commonmark", "testdata/commonmark_spec.json", nil, commonMarkRoundTripSkips},
+		{"gfm", "testdata/gfm_fixture.json", []ParseOption{WithGFM()}, gfmRoundTripSkips},
+	}
+	for _, suite := range suites {
+		for _, ex := range loadSpecExamples(t, suite.path) {
+			ex := ex
+			t.Run(fmt.Sprintf("%s/%s/%d", suite.name, ex.Section, ex.Example), func(t *testing.T) {
+				if reason, ok := suite.skips[ex.Example]; ok {
+					t.Skip(reason)
+				}
+				root, err := Parse(context.Background(), []byte(ex.Markdown), suite.opts...)
+				require.NoError(t, err)
+				md, err := root.ToMarkdown(context.Background())
+				require.NoError(t, err)
+				again, err := Parse(context.Background(), []byte(md), suite.opts...)
+				require.NoError(t, err)
+				eq, diff := Equal(root, again, EqualOptions{IgnorePosition: true})
+				assert.True(t, eq, "%s\nmarkdown: %q\noutput: %q", diff, ex.Markdown, md)
+			})
+		}
+	}
+}
+
+func TestParseCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Parse(ctx, []byte("# Title\n"))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// renderSpecHTML 按 CommonMark 参考实现的格式输出 HTML，仅用于比对规范示例
+func renderSpecHTML(root *Node) string {
+	r := &specRenderer{defs: map[string]*Node{}, notes: map[string]*Node{}, refs: map[string]int{}}
+	r.collectDefinitions(root)
+	r.flow(root.FlowChildren, false)
+	r.footnotes()
+	return r.sb.String()
+}
+
+type specRenderer struct {
+	sb    strings.Builder
+	defs  map[string]*Node
+	notes map[string]*Node
+	// order 是脚注按首次引用排列的标识符，refs 记录每个脚注被引用的次数
+	order []string
+	refs  map[string]int
+}
+
+func (r *specRenderer) collectDefinitions(n *Node) {
+	if n.Type == NodeDefinition || n.Type == NodeFootnoteDefinition {
+		defs := r.defs
+		if n.Type == NodeFootnoteDefinition {
+			defs = r.notes
+		}
+		id, _ := n.Data.GetString(NDK_Identifier)
+		if _, ok := defs[id]; !ok {
+			defs[id] = n
+		}
+	}
+	for _, c := range n.FlowChildren {
+		r.collectDefinitions(c.(*Node))
+	}
+	for _, c := range n.ListChildren {
+		r.collectDefinitions(c.(*Node))
+	}
+}
+
+func (r *specRenderer) out(s string) { r.sb.WriteString(s) }
+
+func (r *specRenderer) cr() {
+	s := r.sb.String()
+	if len(s) > 0 && !strings.HasSuffix(s, "\n") {
+		r.sb.WriteString("\n")
+	}
+}
+
+func (r *specRenderer) flow(children []FlowContent, tight bool) {
+	for _, c := range children {
+		r.block(c.(*Node), tight)
+	}
+}
+
+func (r *specRenderer) block(n *Node, tight bool) {
+	switch n.Type {
+	case NodeParagraph:
+		if tight {
+			r.inlines(n.PhrasingChildren)
+			return
+		}
+		r.cr()
+		r.out("<p>")
+		r.inlines(n.PhrasingChildren)
+		r.out("</p>")
+		r.cr()
+	case NodeHeading:
+		depth, _ := n.Data.GetInt(NDK_Depth)
+		r.cr()
+		r.out(fmt.Sprintf("<h%d>", depth))
+		r.inlines(n.PhrasingChildren)
+		r.out(fmt.Sprintf("</h%d>", depth))
+		r.cr()
+	case NodeThematicBreak:
+		r.cr()
+		r.out("<hr />")
+		r.cr()
+	case NodeBlockquote:
+		r.cr()
+		r.out("<blockquote>")
+		r.cr()
+		r.flow(n.FlowChildren, false)
+		r.cr()
+		r.out("</blockquote>")
+		r.cr()
+	case NodeList:
+		ordered, _ := n.Data.GetBool(NDK_Ordered)
+		spread, _ := n.Data.GetBool(NDK_Spread)
+		tag := "ul"
+		r.cr()
+		if ordered {
+			tag = "ol"
+			if start, _ := n.Data.GetInt(NDK_Start); start != 1 {
+				r.out(fmt.Sprintf("<ol start=\"%d\">", start))
+			} else {
+				r.out("<ol>")
+			}
+		} else {
+			r.out("<ul>")
+		}
+		r.cr()
+		for _, item := range n.ListChildren {
+			r.out("<li>")
+			if checked, ok := item.(*Node).Data.GetBool(NDK_Checked); ok {
+				if checked {
+					r.out(`<input checked="" disabled="" type="checkbox"> `)
+				} else {
+					r.out(`<input disabled="" type="checkbox"> `)
+				}
+			}
+			r.flow(item.(*Node).FlowChildren, !spread)
+			r.out("</li>")
+			r.cr()
+		}
+		r.out("</" + tag + ">")
+		r.cr()
+	case NodeCode:
+		r.cr()
+		if lang, _ := n.Data.GetString(NDK_Lang); lang != "" {
+			r.out(`<pre><code class="language-` + specEscape(lang) + `">`)
+		} else {
+			r.out("<pre><code>")
+		}
+		if n.Value != "" {
+			r.out(specEscape(n.Value) + "\n")
+		}
+		r.out("</code></pre>")
+		r.cr()
+	case NodeHTML:
+		r.cr()
+		r.out(n.Value)
+		r.cr()
+	case NodeTable:
+		align, _ := n.Data[NDK_Align].([]AlignType)
+		r.cr()
+		r.out("<table>\n")
+		for i, row := range n.TableChildren {
+			tag := "td"
+			if i == 0 {
+				tag = "th"
+				r.out("<thead>\n")
+			} else if i == 1 {
+				r.out("<tbody>\n")
+			}
+			r.out("<tr>\n")
+			cells := row.(*Node).TableChildren
+			for j := range align {
+				r.out("<" + tag)
+				if align[j] != AlignNone {
+					r.out(` align="` + string(align[j]) + `"`)
+				}
+				r.out(">")
+				if j < len(cells) {
+					r.inlines(cells[j].(*Node).PhrasingChildren)
+				}
+				r.out("</" + tag + ">\n")
+			}
+			r.out("</tr>\n")
+			if i == 0 {
+				r.out("</thead>\n")
+			}
+		}
+		if len(n.TableChildren) > 1 {
+			r.out("</tbody>\n")
+		}
+		r.out("</table>\n")
+	}
+}
+
+func (r *specRenderer) inlines(children []PhrasingContent) {
+	for _, c := range children {
+		r.inline(c.(*Node))
+	}
+}
+
+func (r *specRenderer) inline(n *Node) {
+	switch n.Type {
+	case NodeText:
+		r.out(specEscape(n.Value))
+	case NodeBreak:
+		r.out("<br />\n")
+	case NodeEmphasis:
+		r.out("<em>")
+		r.inlines(n.PhrasingChildren)
+		r.out("</em>")
+	case NodeStrong:
+		r.out("<strong>")
+		r.inlines(n.PhrasingChildren)
+		r.out("</strong>")
+	case NodeDelete:
+		r.out("<del>")
+		r.inlines(n.PhrasingChildren)
+		r.out("</del>")
+	case NodeInlineCode:
+		r.out("<code>" + specEscape(n.Value) + "</code>")
+	case NodeHTML:
+		r.out(n.Value)
+	case NodeLink, NodeLinkReference:
+		url, title := r.target(n)
+		r.out(`<a href="` + specEscape(normalizeURL(url)) + `"`)
+		if title != "" {
+			r.out(` title="` + specEscape(title) + `"`)
+		}
+		r.out(">")
+		r.inlines(n.PhrasingChildren)
+		r.out("</a>")
+	case NodeFootnoteReference:
+		id, _ := n.Data.GetString(NDK_Identifier)
+		def, ok := r.notes[id]
+		if !ok {
+			r.out(specEscape("[^" + n.Label() + "]"))
+			return
+		}
+		if r.refs[id] == 0 {
+			r.order = append(r.order, id)
+		}
+		r.refs[id]++
+		label := specEscape(def.Label())
+		refID := "fnref-" + label
+		if k := r.refs[id]; k > 1 {
+			refID += fmt.Sprintf("-%d", k)
+		}
+		num := slices.Index(r.order, id) + 1
+		r.out(fmt.Sprintf(`<sup class="footnote-ref"><a href="#fn-%s" id="%s" data-footnote-ref>%d</a></sup>`, label, refID, num))
+	case NodeImage, NodeImageReference:
+		url, title := r.target(n)
+		alt, _ := n.Data.GetString(NDK_Alt)
+		r.out(`<img src="` + specEscape(normalizeURL(url)) + `" alt="` + specEscape(alt) + `"`)
+		if title != "" {
+			r.out(` title="` + specEscape(title) + `"`)
+		}
+		r.out(" />")
+	}
+}
+
+// footnotes 按 cmark-gfm 的格式在文末输出被引用的脚注，返回链接放在最后一个段落末尾
+func (r *specRenderer) footnotes() {
+	if len(r.order) == 0 {
+		return
+	}
+	r.cr()
+	r.out("<section class=\"footnotes\" data-footnotes>\n<ol>\n")
+	// 脚注内容中可能引用新的脚注，order 会在循环中增长
+	for i := 0; i < len(r.order); i++ {
+		id := r.order[i]
+		def := r.notes[id]
+		label := specEscape(def.Label())
+		r.out(`<li id="fn-` + label + `">` + "\n")
+		var backrefs []string
+		for k := 1; k <= r.refs[id]; k++ {
+			if k == 1 {
+				backrefs = append(backrefs, fmt.Sprintf(`<a href="#fnref-%s" class="footnote-backref" data-footnote-backref data-footnote-backref-idx="%d" aria-label="Back to reference %d">↩</a>`, label, i+1, i+1))
+				continue
+			}
+			backrefs = append(backrefs, fmt.Sprintf(`<a href="#fnref-%s-%d" class="footnote-backref" data-footnote-backref data-footnote-backref-idx="%d-%d" aria-label="Back to reference %d-%d">↩<sup class="footnote-ref">%d</sup></a>`, label, k, i+1, k, i+1, k, k))
+		}
+		r.flow(def.FlowChildren, false)
+		if s := r.sb.String(); strings.HasSuffix(s, "</p>\n") {
+			r.sb.Reset()
+			r.out(strings.TrimSuffix(s, "</p>\n") + " " + strings.Join(backrefs, " ") + "</p>\n")
+		} else {
+			r.out(strings.Join(backrefs, " ") + "\n")
+		}
+		r.out("</li>\n")
+	}
+	r.out("</ol>\n</section>\n")
+}
+
+func (r *specRenderer) target(n *Node) (string, string) {
+	if n.Type == NodeLinkReference || n.Type == NodeImageReference {
+		id, _ := n.Data.GetString(NDK_Identifier)
+		if def, ok := r.defs[id]; ok {
+			n = def
+		}
+	}
+	url, _ := n.Data.GetString(NDK_URL)
+	title, _ := n.Data.GetString(NDK_Title)
+	return url, title
+}
+
+func specEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
@@ -0,0 +1,106 @@
+package mdast
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePositions(t *testing.T) {
+	src := "# Hi *there*\n\n[a]: /u\nsome **bold** [l](/x)  \nnext\n\n- item\n- [x] task\n\n| a | b |\n|---|--:|\n| *x* | y |\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+
+	pos := func(n Content) string { return n.(*Node).Position.String() }
+	assert.Equal(t, "1:1-13:1", root.Position.String())
+	assert.Equal(t, len(src), root.Position.End.Offset)
+
+	heading := root.FlowChildren[0].(*Node)
+	assert.Equal(t, "1:1-1:13", pos(heading))
+	assert.Equal(t, "1:6-1:13", pos(heading.PhrasingChildren[1]))
+	assert.Equal(t, "1:7-1:12", pos(heading.PhrasingChildren[1].(*Node).PhrasingChildren[0]))
+
+	def := root.FlowChildren[1].(*Node)
+	assert.Equal(t, NodeDefinition, def.Type)
+	assert.Equal(t, "3:1-3:8", pos(def))
+
+	para := root.FlowChildren[2].(*Node)
+	assert.Equal(t, "4:1-5:5", pos(para))
+	assert.Equal(t, "4:6-4:14", pos(para.PhrasingChildren[1]))
+	link := para.PhrasingChildren[3].(*Node)
+	assert.Equal(t, NodeLink, link.Type)
+	assert.Equal(t, "4:15-4:22", link.Position.String())
+	assert.Equal(t, 14+8+14, link.Position.Start.Offset)
+	assert.Equal(t, "4:22-5:1", pos(para.PhrasingChildren[4]))
+
+	list := root.FlowChildren[3].(*Node)
+	assert.Equal(t, "7:1-8:11", pos(list))
+	task := list.ListChildren[1].(*Node)
+	assert.Equal(t, "8:1-8:11", pos(task))
+	assert.Equal(t, "8:7-8:11", pos(task.FlowChildren[0].(*Node).PhrasingChildren[0]))
+
+	table := root.FlowChildren[4].(*Node)
+	assert.Equal(t, "10:1-12:12", pos(table))
+	row := table.TableChildren[1].(*Node)
+	assert.Equal(t, "12:1-12:12", pos(row))
+	assert.Equal(t, "12:3-12:6", pos(row.TableChildren[0]))
+	assert.Equal(t, "12:4-12:5", pos(row.TableChildren[0].(*Node).PhrasingChildren[0].(*Node).PhrasingChildren[0]))
+
+	// 列号按码位计算，偏移按字节计算
+	root, err = Parse(context.Background(), []byte("Crème *brûlée* x\n| é | b |\n"))
+	require.NoError(t, err)
+	para = root.FlowChildren[0].(*Node)
+	assert.Equal(t, "1:1-2:10", pos(para))
+	em := para.PhrasingChildren[1].(*Node)
+	assert.Equal(t, "1:7-1:15", em.Position.String())
+	assert.Equal(t, 7, em.Position.Start.Offset)
+	assert.Equal(t, 17, em.Position.End.Offset)
+	assert.Equal(t, "1:15-2:10", pos(para.PhrasingChildren[2]))
+	assert.Equal(t, "1:1-3:1", root.Position.String())
+}
+
+func TestSerializeErrorPosition(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("intro\n\nsee [docs][ref] here\n\n[ref]: /docs\n"))
+	require.NoError(t, err)
+
+	ref := root.FlowChildren[1].(*Node).PhrasingChildren[1].(*Node)
+	require.Equal(t, NodeLinkReference, ref.Type)
+	delete(ref.Data, NDK_Identifier)
+
+	_, err = root.ToMarkdown(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3:5-3:16")
+
+	var pe *PositionError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, NodeLinkReference, pe.Type)
+	assert.Equal(t, 3, pe.Position.Start.Line)
+	assert.Equal(t, 5, pe.Position.Start.Column)
+
+	// 单独转换列表和表格时同样带上位置，表格中的错误带有最内层节点的位置
+	root, err = Parse(context.Background(), []byte("- a\n\n| a | b |\n| - | - |\n| [x][ref] | y |\n\n[ref]: /x\n"), WithGFM())
+	require.NoError(t, err)
+	list := root.FlowChildren[0].(*Node)
+	list.ListChildren = append(list.ListChildren, &Node{Type: NodeParagraph})
+	_, err = list.ToMarkdown(context.Background())
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, NodeList, pe.Type)
+	table := root.FlowChildren[1].(*Node)
+	cell := table.TableChildren[1].(*Node).TableChildren[1].(*Node)
+	cell.AddPhrasingChild(&Node{Type: "custom"})
+	for _, n := range []*Node{table, table.TableChildren[1].(*Node), cell} {
+		_, err = n.ToMarkdown(context.Background())
+		require.True(t, errors.As(err, &pe), "%s: %v", n.Type, err)
+		assert.Equal(t, NodeTableCell, pe.Type)
+		assert.Same(t, cell.Position, pe.Position)
+	}
+
+	// 手工构建的节点没有位置，错误保持原样
+	n := NewNode(NodeLink)
+	_, err = n.ToMarkdown(context.Background())
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &pe))
+}
@@ -0,0 +1,109 @@
+package mdast
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperText 是测试用插件，将所有文本转换为大写
+var upperText = TransformerFunc(func(ctx context.Context, root *Node) error {
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		n.Value = strings.ToUpper(n.Value)
+		return Continue
+	}, NodeText)
+	return nil
+})
+
+func TestProcessorProcess(t *testing.T) {
+	stripHTML := TransformerFunc(func(ctx context.Context, root *Node) error {
+		kept := []FlowContent{}
+		for _, child := range root.FlowChildren {
+			if child.GetType() != NodeHTML {
+				kept = append(kept, child)
+			}
+		}
+		root.FlowChildren = kept
+		return nil
+	})
+
+	out, err := NewProcessor().Use(upperText, stripHTML).Process(context.Background(), []byte("# hello\n\n<div>x</div>\n\nworld\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "# HELLO\n\nWORLD\n\n", out)
+}
+
+func TestProcessorRenderer(t *testing.T) {
+	p := NewProcessor(WithGFM()).UseRenderer(func(ctx context.Context, root *Node) (string, error) {
+		return string(root.FlowChildren[0].GetType()), nil
+	})
+	out, err := p.Process(context.Background(), []byte("| a |\n|---|\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "table", out)
+}
+
+func TestProcessorErrorAggregation(t *testing.T) {
+	errFirst := errors.New("first failed")
+	errThird := errors.New("third failed")
+	var ran []int
+	p := NewProcessor().Use(
+		Named("first", TransformerFunc(func(ctx context.Context, root *Node) error {
+			ran = append(ran, 0)
+			return errFirst
+		})),
+		TransformerFunc(func(ctx context.Context, root *Node) error {
+			ran = append(ran, 1)
+			return nil
+		}),
+		TransformerFunc(func(ctx context.Context, root *Node) error {
+			ran = append(ran, 2)
+			return errThird
+		}),
+	)
+
+	_, err := p.Process(context.Background(), []byte("text\n"))
+	require.Error(t, err)
+	assert.Equal(t, []int{0, 1, 2}, ran)
+	assert.ErrorIs(t, err, errFirst)
+	assert.ErrorIs(t, err, errThird)
+	assert.Contains(t, err.Error(), "plugin 0 (first): first failed")
+	assert.Contains(t, err.Error(), "plugin 2 (mdast.TransformerFunc): third failed")
+
+	var pe *PluginError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 0, pe.Index)
+}
+
+type ctxKey struct{}
+
+func TestProcessorContext(t *testing.T) {
+	var seen any
+	p := NewProcessor().Use(TransformerFunc(func(ctx context.Context, root *Node) error {
+		seen = ctx.Value(ctxKey{})
+		return nil
+	}))
+	_, err := p.Process(context.WithValue(context.Background(), ctxKey{}, "v"), []byte("x\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "v", seen)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	p = NewProcessor().Use(
+		TransformerFunc(func(ctx context.Context, root *Node) error {
+			calls++
+			cancel()
+			return nil
+		}),
+		TransformerFunc(func(ctx context.Context, root *Node) error {
+			calls++
+			return nil
+		}),
+	)
+	root := NewNode(NodeRoot)
+	err = p.Run(ctx, root)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
@@ -13,12 +13,13 @@ func TestReferenceElements(t *testing.T) {
 		{"Definition", createDefinitionNode("example", "https://example.com", "Example Title"), "[example]: https://example.com \"Example Title\"\n", false},
 		{"Definition without title", createDefinitionNode("example", "https://example.com", ""), "[example]: https://example.com\n", false},
 		{"ImageReference full", createImageReferenceNode("example", "Alt Text", "full"), "![Alt Text][example]", false},
-		{"ImageReference collapsed", createImageReferenceNode("example", "Alt Text", "collapsed"), "![Alt Text][]", false},
-		{"ImageReference shortcut", createImageReferenceNode("example", "Alt Text", "shortcut"), "![Alt Text]", false},
+		{"ImageReference collapsed", createImageReferenceNode("example", "Alt Text", "collapsed"), "![Alt Text][example]", false},
+		{"ImageReference shortcut", createImageReferenceNode("example", "Alt Text", "shortcut"), "![Alt Text][example]", false},
 		{"LinkReference full", createLinkReferenceNode("example", "Link Text", "full"), "[Link Text][example]", false},
-		{"LinkReference collapsed", createLinkReferenceNode("example", "Link Text", "collapsed"), "[Link Text][]", false},
-		{"LinkReference shortcut", createLinkReferenceNode("example", "Link Text", "shortcut"), "[Link Text]", false},
-		{"Footnote", createFootnoteNode("Footnote content"), "[^Footnote content]", false},
+		{"LinkReference collapsed", createLinkReferenceNode("example", "Link Text", "collapsed"), "[Link Text][example]", false},
+		{"LinkReference shortcut", createLinkReferenceNode("example", "Link Text", "shortcut"), "[Link Text][example]", false},
+		{"Footnote", createFootnoteNode("Footnote content"), "^[Footnote content]", false},
+		{"Footnote with brackets", createFootnoteNode("a [b]"), `^[a \[b\]]`, false},
 		{"FootnoteReference", createFootnoteReferenceNode("1"), "[^1]", false},
 		{"FootnoteDefinition", createFootnoteDefinitionNode("1", "Footnote content"), "[^1]: Footnote content\n\n", false},
 	}
@@ -43,8 +44,9 @@ func TestReferenceTypes(t *testing.T) {
 		referenceType ReferenceType
 		expected      string
 	}{
-		{"Shortcut", "shortcut", "[Link]"},
-		{"Collapsed", "collapsed", "[Link][]"},
+		// 标签与链接文本不同时，简写和折叠形式无法指向定义，改用完整形式
+		{"Shortcut", "shortcut", "[Link][id]"},
+		{"Collapsed", "collapsed", "[Link][id]"},
 		{"Full", "full", "[Link][id]"},
 	}
 
@@ -79,3 +81,46 @@ func ExampleNode_ToMarkdown_imageReference() {
 	fmt.Print(markdown)
 	// Output: ![Alt Text][example]
 }
+
+func TestReferenceLabels(t *testing.T) {
+	ctx := context.Background()
+	src := "[Foo*] [Foo*][] [bar][Foo*] ![*Logo*] ![a][*Logo*]\n\n[Foo*]: /url\n[*Logo*]: /logo.png\n"
+	root, err := Parse(ctx, []byte(src))
+	assert.NoError(t, err)
+	md, err := root.ToMarkdown(ctx)
+	assert.NoError(t, err)
+	// 标签原样输出，定义使用源文中的标签而不是规范化后的标识符
+	assert.Equal(t, src, md)
+
+	// 脚注引用和脚注定义同样使用标签
+	src = "Note[^A].\n\n[^A]: Text.\n\n"
+	root, err = Parse(ctx, []byte(src), WithGFM())
+	assert.NoError(t, err)
+	md, err = root.ToMarkdown(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, src, md)
+	md, err = NewFootnoteReference("B").ToMarkdown(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "[^B]", md)
+	// 没有标签时使用标识符
+	md, err = (&Node{Type: NodeFootnoteReference, Data: DataTable{NDK_Identifier: "c"}}).ToMarkdown(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "[^c]", md)
+
+	// 标签首尾的空白不影响简写和折叠形式
+	src = "[ 1] [ 1][]\n\n[ 1]: u\n"
+	root, err = Parse(ctx, []byte(src))
+	assert.NoError(t, err)
+	md, err = root.ToMarkdown(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, src, md)
+
+	// 子节点与标签不一致时改用完整形式
+	ref := NewNode(NodeLinkReference)
+	assert.NoError(t, ref.SetLabel("Foo"))
+	ref.SetData(NDK_ReferenceType, ReferenceShortcut)
+	ref.AddPhrasingChild(NewText("bar"))
+	md, err = ref.ToMarkdown(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "[bar][Foo]", md)
+}
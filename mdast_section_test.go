@@ -0,0 +1,117 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sectionSource = "Intro text\n\n# Project\n\nAbout.\n\n## Installation\n\nRun it.\n\n### From source\n\nBuild it.\n\n## Usage\n\nUse it.\n\n> ## Quoted\n\n# Appendix\n"
+
+func parseSections(t *testing.T) *Node {
+	root, err := Parse(context.Background(), []byte(sectionSource))
+	require.NoError(t, err)
+	return root
+}
+
+func sectionMarkdown(t *testing.T, root *Node) string {
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	return md
+}
+
+func TestSplitSections(t *testing.T) {
+	top := SplitSections(parseSections(t))
+	assert.Nil(t, top.Heading)
+	assert.Equal(t, 0, top.Depth())
+	require.Len(t, top.Content, 1)
+	assert.Equal(t, "Intro text", ToString(top.Content[0], StringOptions{}))
+	require.Len(t, top.Subsections, 2)
+
+	project := top.Subsections[0]
+	assert.Equal(t, "project", project.ID())
+	require.Len(t, project.Content, 1)
+	assert.Equal(t, "About.", ToString(project.Content[0], StringOptions{}))
+	require.Len(t, project.Subsections, 2)
+
+	install, usage := project.Subsections[0], project.Subsections[1]
+	assert.Equal(t, 2, install.Depth())
+	require.Len(t, install.Subsections, 1)
+	assert.Equal(t, "from-source", install.Subsections[0].ID())
+	// 引用块中的标题不划分节
+	assert.Len(t, usage.Content, 2)
+	assert.Empty(t, usage.Subsections)
+
+	assert.Equal(t, "appendix", top.Subsections[1].ID())
+	assert.Len(t, top.Nodes(), len(parseSections(t).FlowChildren))
+
+	assert.Same(t, install, top.Find("installation"))
+	assert.Same(t, install, top.Find(" Installation "))
+	assert.Same(t, install.Subsections[0], top.Find("from-source"))
+	assert.Nil(t, top.Find("Quoted"))
+}
+
+func TestExtractSection(t *testing.T) {
+	root := parseSections(t)
+	before := sectionMarkdown(t, root)
+	section, err := ExtractSection(root, "Installation")
+	require.NoError(t, err)
+	assert.Equal(t, "## Installation\n\nRun it.\n\n### From source\n\nBuild it.\n\n", sectionMarkdown(t, section))
+	assert.Equal(t, before, sectionMarkdown(t, root))
+	for _, child := range section.FlowChildren {
+		assert.Same(t, section, child.(*Node).Parent())
+	}
+
+	_, err = ExtractSection(root, "Missing")
+	assert.EqualError(t, err, `section "Missing" not found`)
+}
+
+func TestReplaceSection(t *testing.T) {
+	root := parseSections(t)
+	old := SplitSections(root).Find("installation").Content[0]
+	require.NoError(t, ReplaceSection(root, "installation", NewParagraph(NewText("go get it"))))
+	assert.Equal(t, "Intro text\n\n# Project\n\nAbout.\n\n## Installation\n\ngo get it\n\n## Usage\n\nUse it.\n\n> ## Quoted\n\n# Appendix\n\n",
+		sectionMarkdown(t, root))
+	assert.Nil(t, old.Parent())
+	assert.Empty(t, Validate(root))
+
+	// 已在文档中的节点被移动过来
+	root = parseSections(t)
+	intro := root.FlowChildren[0].(*Node)
+	require.NoError(t, ReplaceSection(root, "Appendix", intro))
+	assert.Equal(t, "# Project\n\nAbout.\n\n## Installation\n\nRun it.\n\n### From source\n\nBuild it.\n\n## Usage\n\nUse it.\n\n> ## Quoted\n\n# Appendix\n\nIntro text\n\n",
+		sectionMarkdown(t, root))
+	assert.Same(t, root, intro.Parent())
+
+	root = parseSections(t)
+	err := ReplaceSection(root, "Project", SplitSections(root).Find("Usage").Heading)
+	assert.EqualError(t, err, `cannot replace section "Project" with its own heading node`)
+}
+
+func TestDeleteSection(t *testing.T) {
+	root := parseSections(t)
+	require.NoError(t, DeleteSection(root, "Installation"))
+	assert.Equal(t, "Intro text\n\n# Project\n\nAbout.\n\n## Usage\n\nUse it.\n\n> ## Quoted\n\n# Appendix\n\n", sectionMarkdown(t, root))
+	require.NoError(t, DeleteSection(root, "project"))
+	assert.Equal(t, "Intro text\n\n# Appendix\n\n", sectionMarkdown(t, root))
+	assert.Error(t, DeleteSection(root, "project"))
+}
+
+func TestMoveSection(t *testing.T) {
+	root := parseSections(t)
+	require.NoError(t, MoveSection(root, "Usage", "Installation"))
+	assert.Equal(t, "Intro text\n\n# Project\n\nAbout.\n\n## Usage\n\nUse it.\n\n> ## Quoted\n\n## Installation\n\nRun it.\n\n### From source\n\nBuild it.\n\n# Appendix\n\n",
+		sectionMarkdown(t, root))
+
+	require.NoError(t, MoveSection(root, "Project", ""))
+	assert.Equal(t, "Intro text\n\n# Appendix\n\n# Project\n\nAbout.\n\n## Usage\n\nUse it.\n\n> ## Quoted\n\n## Installation\n\nRun it.\n\n### From source\n\nBuild it.\n\n",
+		sectionMarkdown(t, root))
+	for _, child := range root.FlowChildren {
+		assert.Same(t, root, child.(*Node).Parent())
+	}
+
+	assert.EqualError(t, MoveSection(root, "Project", "From source"), `cannot move section "Project" before "From source" inside itself`)
+	assert.EqualError(t, MoveSection(root, "Project", "Missing"), `section "Missing" not found`)
+}
@@ -0,0 +1,134 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const styleSource = "# Title\n\n## Sub\n\n### Third\n\nSome *em* and **strong**.\n\n```go\nx\n```\n\n***\n"
+
+func TestSerializeOptionsDefault(t *testing.T) {
+	root, err := Parse(context.Background(), []byte(styleSource))
+	require.NoError(t, err)
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "# Title\n\n## Sub\n\n### Third\n\nSome *em* and **strong**.\n\n```go\nx\n```\n\n---\n\n", md)
+}
+
+func TestSerializeOptionsExplicit(t *testing.T) {
+	root, err := Parse(context.Background(), []byte(styleSource))
+	require.NoError(t, err)
+	md, err := ToMarkdownWithOptions(context.Background(), root, SerializeOptions{
+		Bullet:         "*",
+		BulletOrdered:  ")",
+		Emphasis:       "_",
+		Strong:         "_",
+		Fence:          "~",
+		Rule:           "*",
+		RuleRepetition: 5,
+		RuleSpaces:     true,
+		Setext:         true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Title\n=====\n\nSub\n---\n\n### Third\n\nSome _em_ and __strong__.\n\n~~~go\nx\n~~~\n\n* * * * *\n\n", md)
+
+	reparsed, err := Parse(context.Background(), []byte(md))
+	require.NoError(t, err)
+	again, err := reparsed.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	want, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, want, again)
+}
+
+func TestSerializeOptionsListBullets(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("- a\n- b\n\n1. one\n2. two\n"))
+	require.NoError(t, err)
+	ctx := WithSerializeOptions(context.Background(), SerializeOptions{Bullet: "+", BulletOrdered: ")"})
+
+	md, err := ListToMarkdown(ctx, root.FlowChildren[0].(*Node))
+	require.NoError(t, err)
+	assert.Contains(t, md, "+ a\n+ b\n")
+	md, err = ListToMarkdown(ctx, root.FlowChildren[1].(*Node))
+	require.NoError(t, err)
+	assert.Contains(t, md, "1) one\n2) two\n")
+}
+
+func TestSerializeOptionsAdjacentLists(t *testing.T) {
+	src := "- a\n* b\n- c\n\n1. one\n1) two\n\n> - d\n"
+	root, err := Parse(context.Background(), []byte(src))
+	require.NoError(t, err)
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	// 相邻的同类列表交替使用两种标记，不相邻的列表仍使用默认标记
+	assert.Equal(t, "- a\n\n* b\n\n- c\n\n1. one\n\n1) two\n\n> - d\n\n", md)
+
+	md, err = ToMarkdownWithOptions(context.Background(), root, SerializeOptions{Bullet: "*", BulletOther: "+", BulletOrdered: ")"})
+	require.NoError(t, err)
+	assert.Equal(t, "* a\n\n+ b\n\n* c\n\n1) one\n\n1. two\n\n> * d\n\n", md)
+}
+
+func TestSerializeOptionsEmphasisMarker(t *testing.T) {
+	ctx := context.Background()
+	underscore := SerializeOptions{Emphasis: "_", Strong: "_"}
+	testCases := []struct {
+		name     string
+		root     *Node
+		opts     SerializeOptions
+		expected string
+	}{
+		// 单词内部的 "_" 不能形成强调，改用 "*"
+		{"Intraword", NewRoot(NewParagraph(NewText("foo"), NewEmphasis(NewText("bar")), NewText("baz"))), underscore, "foo*bar*baz\n\n"},
+		{"Intraword strong", NewRoot(NewParagraph(NewText("foo"), NewStrong(NewText("bar")), NewText("baz"))), underscore, "foo**bar**baz\n\n"},
+		{"Between words", NewRoot(NewParagraph(NewText("foo "), NewEmphasis(NewText("bar")), NewText(" baz"))), underscore, "foo _bar_ baz\n\n"},
+		// 嵌套的强调换用另一种标记，否则会被解析为加粗
+		{"Nested", NewRoot(NewParagraph(NewEmphasis(NewEmphasis(NewText("foo"))))), SerializeOptions{}, "*_foo_*\n\n"},
+		{"Nested underscore", NewRoot(NewParagraph(NewEmphasis(NewEmphasis(NewText("foo"))))), underscore, "_*foo*_\n\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md, err := ToMarkdownWithOptions(ctx, tc.root, tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, md)
+			parsed, err := Parse(ctx, []byte(md))
+			require.NoError(t, err)
+			equal, diff := Equal(tc.root, parsed, EqualOptions{IgnorePosition: true})
+			assert.True(t, equal, diff)
+		})
+	}
+}
+
+func TestSerializeOptionsContext(t *testing.T) {
+	ctx := WithSerializeOptions(context.Background(), SerializeOptions{Emphasis: "_", CloseAtx: true})
+	md, err := createHeadingNode(2, "Title").ToMarkdown(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "## Title ##\n\n", md)
+
+	em := NewNode(NodeEmphasis)
+	em.AddPhrasingChild(&Node{Type: NodeText, Value: "x"})
+	md, err = InlineToMarkdown(ctx, em)
+	require.NoError(t, err)
+	assert.Equal(t, "_x_", md)
+
+	opts, err := SerializeOptionsFrom(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "-", opts.Bullet)
+	assert.Equal(t, "_", opts.Emphasis)
+}
+
+func TestSerializeOptionsInvalid(t *testing.T) {
+	list := createListNode(false, "item")
+	_, err := ToMarkdownWithOptions(context.Background(), list, SerializeOptions{Bullet: "x"})
+	assert.ErrorContains(t, err, `invalid bullet "x"`)
+	_, err = ToMarkdownWithOptions(context.Background(), list, SerializeOptions{Bullet: "*", BulletOther: "*"})
+	assert.ErrorContains(t, err, `invalid other bullet "*"`)
+	_, err = ToMarkdownWithOptions(context.Background(), list, SerializeOptions{BulletOrderedOther: "."})
+	assert.ErrorContains(t, err, `invalid other ordered bullet "."`)
+
+	rule := NewNode(NodeThematicBreak)
+	_, err = ToMarkdownWithOptions(context.Background(), rule, SerializeOptions{RuleRepetition: 2})
+	assert.ErrorContains(t, err, "invalid rule repetition 2")
+}
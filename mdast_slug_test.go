@@ -0,0 +1,87 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlug(t *testing.T) {
+	cases := map[string]string{
+		"Hello, World!":         "hello-world",
+		"  Leading space":       "--leading-space",
+		"snake_case and-dash":   "snake_case-and-dash",
+		"Ünïcödé Straße":        "ünïcödé-straße",
+		"中文 标题":                 "中文-标题",
+		"emoji 🎉 party":         "emoji--party",
+		"v1.2.3 (beta)":         "v123-beta",
+		"`code` & <b>tags</b>?": "code--btagsb",
+		"🎉":                     "",
+	}
+	for text, want := range cases {
+		assert.Equal(t, want, Slug(text), text)
+	}
+}
+
+func TestSlugger(t *testing.T) {
+	s := NewSlugger()
+	assert.Equal(t, "foo", s.Slug("Foo"))
+	assert.Equal(t, "foo-1", s.Slug("foo"))
+	assert.Equal(t, "foo-2", s.Slug("FOO"))
+	// 原文本身就是 foo-1 时与已生成的后缀冲突
+	assert.Equal(t, "foo-1-1", s.Slug("foo-1"))
+
+	s = NewSlugger()
+	s.Reserve("intro")
+	assert.Equal(t, "intro-1", s.Slug("Intro"))
+	assert.Equal(t, "", s.Slug("🎉"))
+	assert.Equal(t, "-1", s.Slug("🎉"))
+}
+
+func headingIDs(root *Node) []string {
+	var ids []string
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		id, _ := n.Data.GetString(NDK_ID)
+		ids = append(ids, id)
+		return Continue
+	}, NodeHeading)
+	return ids
+}
+
+func TestHeadingIDs(t *testing.T) {
+	src := "# Intro\n\n## Setup *with* `go`\n\n## Intro\n\n> ### Quoted ![logo](x.png)\n\n## Intro\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+	require.NoError(t, HeadingIDs().Transform(context.Background(), root))
+	assert.Equal(t, []string{"intro", "setup-with-go", "intro-1", "quoted-logo", "intro-2"}, headingIDs(root))
+
+	// 重复执行不改变已有的 id
+	require.NoError(t, HeadingIDs().Transform(context.Background(), root))
+	assert.Equal(t, []string{"intro", "setup-with-go", "intro-1", "quoted-logo", "intro-2"}, headingIDs(root))
+
+	// 手动设置的 id 被保留，生成的 id 避开它
+	root = NewRoot(NewHeading(1, NewText("Usage")), NewHeading(2, NewText("Other")))
+	root.FlowChildren[1].(*Node).SetData(NDK_ID, "usage")
+	require.NoError(t, HeadingIDs().Transform(context.Background(), root))
+	assert.Equal(t, []string{"usage-1", "usage"}, headingIDs(root))
+}
+
+func TestHeadingIDsHTML(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("# A \"quoted\" title\n\n## A \"quoted\" title\n"))
+	require.NoError(t, err)
+	out, err := ToHTML(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>A &quot;quoted&quot; title</h1>\n<h2>A &quot;quoted&quot; title</h2>\n", out)
+
+	require.NoError(t, HeadingIDs().Transform(context.Background(), root))
+	out, err = ToHTML(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, "<h1 id=\"a-quoted-title\">A &quot;quoted&quot; title</h1>\n<h2 id=\"a-quoted-title-1\">A &quot;quoted&quot; title</h2>\n", out)
+
+	// id 存放在 JSON 的 data 字段中
+	data, err := root.FlowChildren[0].(*Node).MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"data":{"id":"a-quoted-title"}`)
+}
@@ -0,0 +1,154 @@
+package mdast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	src := "# Report\n\n> quote\n>\n> - item\n>\n>   ```\n>   code\n>   ```\n\n1. one\n2. two\n   - nested\n\n[^1]: note\n\n    more\n\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, root.WriteMarkdown(context.Background(), &buf))
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, md, buf.String())
+	assert.Equal(t, src, buf.String())
+
+	// 单独的块和行内节点也可以写出
+	buf.Reset()
+	require.NoError(t, NewStrong(NewText("x")).WriteMarkdown(context.Background(), &buf))
+	assert.Equal(t, "**x**", buf.String())
+}
+
+func TestWriteMarkdownSpec(t *testing.T) {
+	for _, ex := range loadSpecExamples(t, "testdata/commonmark_spec.json") {
+		root, err := Parse(context.Background(), []byte(ex.Markdown))
+		require.NoError(t, err)
+		md, mdErr := root.ToMarkdown(context.Background())
+		var buf bytes.Buffer
+		err = root.WriteMarkdown(context.Background(), &buf)
+		if mdErr != nil {
+			assert.Error(t, err, "example %d", ex.Example)
+			continue
+		}
+		require.NoError(t, err, "example %d", ex.Example)
+		assert.Equal(t, md, buf.String(), "example %d", ex.Example)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteMarkdownErrors(t *testing.T) {
+	root := NewRoot(NewParagraph(NewText("x")))
+	assert.EqualError(t, root.WriteMarkdown(context.Background(), failingWriter{}), "disk full")
+
+	list := &Node{Type: NodeList, ListChildren: []ListContent{&Node{Type: NodeParagraph}},
+		Position: &Position{Start: Point{Line: 3, Column: 1}, End: Point{Line: 4, Column: 1}}}
+	root = NewRoot(NewBlockquote(list))
+	err := root.WriteMarkdown(context.Background(), io.Discard)
+	var pe *PositionError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, NodeList, pe.Type)
+}
+
+func TestWriteMarkdownThematicBreak(t *testing.T) {
+	// 紧凑列表项中段落之后的分隔线前要有空行，否则会成为 setext 标题
+	root := NewRoot(NewList(false,
+		NewListItem(NewParagraph(NewText("a")), NewThematicBreak()),
+		NewListItem(NewThematicBreak()),
+	))
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	// 分隔线与列表标记在同一行时换用其他字符
+	assert.Equal(t, "- a\n\n  ---\n- ***\n\n", md)
+
+	md, err = ToMarkdownWithOptions(context.Background(), NewList(false, NewListItem(NewList(false, NewListItem(NewThematicBreak())))),
+		SerializeOptions{Bullet: "*", Rule: "*"})
+	require.NoError(t, err)
+	assert.Equal(t, "* * ---\n\n", md)
+}
+
+// nestedDocument 生成 depth 层嵌套的引用块和列表，每层包含 lines 行文本
+func nestedDocument(depth, lines int) *Node {
+	para := func(level int) *Node {
+		var sb strings.Builder
+		for i := 0; i < lines; i++ {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			fmt.Fprintf(&sb, "level %d line %d", level, i)
+		}
+		return NewParagraph(NewText(sb.String()))
+	}
+	inner := NewBlockquote(para(depth))
+	for level := depth - 1; level > 0; level-- {
+		if level%2 == 0 {
+			inner = NewBlockquote(para(level), inner)
+		} else {
+			inner = NewList(false, NewListItem(para(level), inner))
+		}
+	}
+	return NewRoot(inner)
+}
+
+// wideDocument 生成 sections 个并列的章节，每个章节包含标题、列表和引用块
+func wideDocument(sections int) *Node {
+	root := NewRoot()
+	for i := 0; i < sections; i++ {
+		root.AddFlowChild(NewHeading(2, NewText(fmt.Sprintf("Section %d", i))))
+		root.AddFlowChild(NewList(true,
+			NewListItem(NewParagraph(NewText("first")), NewBlockquote(NewParagraph(NewText("quoted")))),
+			NewListItem(NewParagraph(NewText("second"))),
+		))
+	}
+	return root
+}
+
+func BenchmarkWriteMarkdownNested(b *testing.B) {
+	for _, depth := range []int{16, 64, 256} {
+		root := nestedDocument(depth, 4)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			var buf bytes.Buffer
+			require.NoError(b, root.WriteMarkdown(context.Background(), &buf))
+			b.SetBytes(int64(buf.Len()))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := root.WriteMarkdown(context.Background(), io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteMarkdownWide(b *testing.B) {
+	for _, sections := range []int{100, 1000, 10000} {
+		root := wideDocument(sections)
+		b.Run(fmt.Sprintf("sections=%d", sections), func(b *testing.B) {
+			var buf bytes.Buffer
+			require.NoError(b, root.WriteMarkdown(context.Background(), &buf))
+			b.SetBytes(int64(buf.Len()))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := root.WriteMarkdown(context.Background(), io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tableMarkdown(t *testing.T, table *Node, opts SerializeOptions) string {
+	md, err := table.ToMarkdown(WithSerializeOptions(context.Background(), opts))
+	require.NoError(t, err)
+	return md
+}
+
+func TestDisplayWidth(t *testing.T) {
+	cases := map[string]int{
+		"":        0,
+		"abc":     3,
+		"中文":      4,
+		"ｆｕｌｌ":    8,
+		"한국어":     6,
+		"e\u0301": 1,
+		"👍":       2,
+		"👍🏽":      2,
+		"👨‍👩‍👧":   2,
+		"❤️":      1,
+		"🇨🇳🇯🇵":    4,
+	}
+	for s, want := range cases {
+		assert.Equal(t, want, displayWidth(s), s)
+	}
+}
+
+func TestTablePipeAlign(t *testing.T) {
+	table := NewTable([]AlignType{AlignLeft, AlignCenter, AlignRight, AlignNone},
+		NewTableRow(
+			NewTableCell(NewText("名称")), NewTableCell(NewText("c")),
+			NewTableCell(NewText("Price")), NewTableCell(NewText("x"))),
+		NewTableRow(
+			NewTableCell(NewText("苹果🍎")), NewTableCell(NewText("mid")),
+			NewTableCell(NewText("1")), NewTableCell()),
+	)
+	md := tableMarkdown(t, table, SerializeOptions{TablePipeAlign: true})
+	assert.Equal(t, ""+
+		"| 名称   |   c   | Price | x   |\n"+
+		"| :----- | :---: | ----: | --- |\n"+
+		"| 苹果🍎 |  mid  |     1 |     |\n\n", md)
+
+	md = tableMarkdown(t, table, SerializeOptions{TablePipeAlign: true, TableCompact: true})
+	assert.Equal(t, ""+
+		"|名称  | c |Price|x|\n"+
+		"|:-----|:-:|----:|-|\n"+
+		"|苹果🍎|mid|    1| |\n\n", md)
+}
+
+func TestTableCompact(t *testing.T) {
+	table := NewTable([]AlignType{AlignLeft, AlignCenter, AlignRight, AlignNone},
+		NewTableRow(NewTableCell(NewText("a")), NewTableCell(NewText("b")),
+			NewTableCell(NewText("c")), NewTableCell(NewText("d"))),
+	)
+	assert.Equal(t, "|a|b|c|d|\n|:-|:-:|-:|-|\n\n", tableMarkdown(t, table, SerializeOptions{TableCompact: true}))
+	assert.Equal(t, "| a | b | c | d |\n| :--- | :---: | ---: | --- |\n\n", tableMarkdown(t, table, SerializeOptions{}))
+}
+
+func TestTableRaggedRows(t *testing.T) {
+	table := NewTable([]AlignType{AlignRight},
+		NewTableRow(NewTableCell(NewText("a")), NewTableCell(NewText("b"))),
+		NewTableRow(NewTableCell(NewText("1"))),
+		NewTableRow(NewTableCell(NewText("1")), NewTableCell(NewText("2")), NewTableCell(NewText("3"))),
+	)
+	assert.Equal(t, "| a | b |\n| ---: | --- |\n| 1 |  |\n| 1 | 2 |\n\n", tableMarkdown(t, table, SerializeOptions{}))
+	assert.Equal(t, ""+
+		"|    a | b   |\n"+
+		"| ---: | --- |\n"+
+		"|    1 |     |\n"+
+		"|    1 | 2   |\n\n", tableMarkdown(t, table, SerializeOptions{TablePipeAlign: true}))
+}
+
+func TestTablePipeEscape(t *testing.T) {
+	table := NewTable([]AlignType{AlignNone, AlignNone},
+		NewTableRow(NewTableCell(NewText("a|b")), NewTableCell(NewInlineCode("x || y"))),
+		NewTableRow(NewTableCell(NewText(`back\|slash`)), NewTableCell(NewLink("https://e.com/?q=a|b", "", NewText("l")))),
+	)
+	md := tableMarkdown(t, table, SerializeOptions{})
+	assert.Equal(t, "| a\\|b | `x \\|\\| y` |\n| --- | --- |\n| back\\\\\\|slash | [l](https://e.com/?q=a\\|b) |\n\n", md)
+
+	for _, opts := range []SerializeOptions{{}, {TablePipeAlign: true}, {TableCompact: true}} {
+		md := tableMarkdown(t, table, opts)
+		root, err := Parse(context.Background(), []byte(md), WithGFM())
+		require.NoError(t, err)
+		require.Len(t, root.FlowChildren, 1)
+		eq, diff := Equal(table, root.FlowChildren[0].(*Node), EqualOptions{IgnorePosition: true, IgnoreParent: true})
+		assert.True(t, eq, "%s\n%s", md, diff)
+	}
+}
+
+func TestTableCellBreak(t *testing.T) {
+	// 单元格中的硬换行输出为 <br>，包括嵌套在强调中的硬换行
+	table := NewTable([]AlignType{AlignNone},
+		NewTableRow(NewTableCell(NewText("a"), NewBreak(), NewStrong(NewText("b"), NewBreak(), NewText("c")))),
+	)
+	assert.Equal(t, "| a<br>**b<br>c** |\n| --- |\n\n", tableMarkdown(t, table, SerializeOptions{}))
+
+	// 其余换行无法在单元格中表示
+	table = NewTable([]AlignType{AlignNone}, NewTableRow(NewTableCell(NewText("a\nb"))))
+	_, err := table.ToMarkdown(context.Background())
+	assert.ErrorContains(t, err, "line endings")
+}
@@ -89,7 +89,10 @@ func createDefinitionNode(identifier, url, title string) *Node {
 	node := NewNode(NodeDefinition)
 	node.SetData(NDK_Identifier, identifier)
 	node.SetData(NDK_URL, url)
-	node.SetData(NDK_Title, title)
+	// 与 NewDefinition 一致，空标题表示没有标题
+	if title != "" {
+		node.SetData(NDK_Title, title)
+	}
 	return node
 }
 
@@ -0,0 +1,139 @@
+package mdast
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTOCSpecContents(t *testing.T) {
+	src, err := os.ReadFile("mdast.def.5.0.0.md")
+	require.NoError(t, err)
+	root, err := Parse(context.Background(), src, WithGFM())
+	require.NoError(t, err)
+
+	toc, err := GenerateTOC(root, TOCOptions{Heading: "Contents", MaxDepth: 3})
+	require.NoError(t, err)
+	md, err := toc.ToMarkdown(context.Background())
+	require.NoError(t, err)
+
+	// 生成的目录与规范文档中手写的目录相同
+	doc := string(src)
+	start := strings.Index(doc, "## Contents\n\n") + len("## Contents\n\n")
+	end := strings.Index(doc, "## Introduction\n")
+	assert.Equal(t, doc[start:end], md)
+}
+
+func TestGenerateTOC(t *testing.T) {
+	src := "# Title\n\n## Install [now](https://e.com)[^1]\n\n#### Deep\n\n## *Usage* `go`\n\n### API\n\n## Usage\n\n> ## Quoted\n\n[^1]: note\n"
+	testCases := []struct {
+		name     string
+		opts     TOCOptions
+		expected string
+	}{
+		{
+			name: "All",
+			expected: "- [Title](#title)\n" +
+				"  - [Install now](#install-now)\n" +
+				"    - - [Deep](#deep)\n" +
+				"  - [*Usage* `go`](#usage-go)\n" +
+				"    - [API](#api)\n" +
+				"  - [Usage](#usage)\n\n",
+		},
+		{
+			name: "DepthRange",
+			opts: TOCOptions{MinDepth: 2, MaxDepth: 3, Ordered: true},
+			expected: "1. [Install now](#install-now)\n" +
+				"2. [*Usage* `go`](#usage-go)\n" +
+				"   1. [API](#api)\n" +
+				"3. [Usage](#usage)\n\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root, err := Parse(context.Background(), []byte(src), WithGFM())
+			require.NoError(t, err)
+			toc, err := GenerateTOC(root, tc.opts)
+			require.NoError(t, err)
+			md, err := toc.ToMarkdown(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, md)
+			assert.Empty(t, Validate(toc))
+		})
+	}
+}
+
+func TestGenerateTOCHeadingIDs(t *testing.T) {
+	root := NewRoot(NewHeading(1, NewText("Intro")), NewHeading(1, NewText("Intro")))
+	root.FlowChildren[0].(*Node).SetData(NDK_ID, "custom")
+	toc, err := GenerateTOC(root, TOCOptions{})
+	require.NoError(t, err)
+	md, err := toc.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "- [Intro](#custom)\n- [Intro](#intro)\n\n", md)
+	_, ok := root.FlowChildren[1].(*Node).Data.GetString(NDK_ID)
+	assert.False(t, ok, "GenerateTOC should not write ids back")
+
+	// 与 HeadingIDs 生成的 id 一致
+	require.NoError(t, HeadingIDs().Transform(context.Background(), root))
+	again, err := GenerateTOC(root, TOCOptions{})
+	require.NoError(t, err)
+	eq, diff := Equal(toc, again, EqualOptions{})
+	assert.True(t, eq, diff)
+}
+
+func TestGenerateTOCReplace(t *testing.T) {
+	src := "# Project\n\n## Contents\n\n- [Old](#old)\n\nstale\n\n## Setup\n\n### Linux\n\n## Usage\n"
+	root, err := Parse(context.Background(), []byte(src))
+	require.NoError(t, err)
+	toc, err := GenerateTOC(root, TOCOptions{Heading: "  contents "})
+	require.NoError(t, err)
+	assert.Same(t, root, toc.Parent())
+
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	expected := "# Project\n\n## Contents\n\n- [Setup](#setup)\n  - [Linux](#linux)\n- [Usage](#usage)\n\n## Setup\n\n### Linux\n\n## Usage\n\n"
+	assert.Equal(t, expected, md)
+
+	// 再次生成结果不变
+	_, err = GenerateTOC(root, TOCOptions{Heading: "Contents"})
+	require.NoError(t, err)
+	md, err = root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expected, md)
+
+	// 没有可收录的标题时不生成目录，原有内容保持不变
+	for _, src := range []string{"# Title\n\n## Contents\n\nold\n\n", "# Title\n\n## Contents\n\nold\n\n## Setup\n\n"} {
+		root, err = Parse(context.Background(), []byte(src))
+		require.NoError(t, err)
+		toc, err = GenerateTOC(root, TOCOptions{Heading: "Contents", MinDepth: 3})
+		require.NoError(t, err)
+		assert.Nil(t, toc)
+		md, err = root.ToMarkdown(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, src, md)
+	}
+
+	// 被替换内容中的标题不收录，也不占用锚点
+	root, err = Parse(context.Background(), []byte("# Doc\n\n## Contents\n\n### Intro\n\ntext\n\n## Intro\n"))
+	require.NoError(t, err)
+	toc, err = GenerateTOC(root, TOCOptions{Heading: "Contents"})
+	require.NoError(t, err)
+	md, err = toc.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "- [Intro](#intro)\n\n", md)
+}
+
+func TestGenerateTOCErrors(t *testing.T) {
+	root := NewRoot(NewHeading(1, NewText("Title")))
+	_, err := GenerateTOC(root, TOCOptions{Heading: "Contents"})
+	assert.EqualError(t, err, `TOC heading "Contents" not found`)
+	_, err = GenerateTOC(root, TOCOptions{MinDepth: 4, MaxDepth: 2})
+	assert.EqualError(t, err, "invalid TOC depth range 4 to 2, expected 1 to 6")
+	_, err = GenerateTOC(root, TOCOptions{MaxDepth: 7})
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToString(t *testing.T) {
+	src := "# Hello *world*\n\nSome `code` and ![an image](a.png) and <b>html</b>.\\\nNext line[^1].\n\n" +
+		"***\n\n> quoted\n>\n> - one\n> - two\n>   1. nested\n\n```go\nfmt.Println()\n```\n\n<div>block</div>\n\n" +
+		"| a | b |\n| - | - |\n| 1 |   |\n| 3 | 4 |\n\n[ref]: https://e.com\n\n[^1]: The note.\n"
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello world\n"+
+		"Some code and an image and html.\nNext line.\n"+
+		"quoted\none\ntwo\nnested\n"+
+		"fmt.Println()\n"+
+		"a\tb\n1\t\n3\t4\n"+
+		"The note.", ToString(root, StringOptions{}))
+
+	assert.Equal(t, "Hello world\n\n"+
+		"Some code and an image and <b>html</b>.\nNext line.\n\n"+
+		"quoted\n\none\n\ntwo\n\nnested\n\n"+
+		"fmt.Println()\n\n<div>block</div>\n\n"+
+		"a\tb\n\n1\t\n\n3\t4\n\n"+
+		"The note.", ToString(root, StringOptions{IncludeHTML: true, BlockSeparator: "\n\n"}))
+}
+
+func TestToStringNodes(t *testing.T) {
+	assert.Equal(t, "", ToString(NewRoot(), StringOptions{}))
+	assert.Equal(t, "body", ToString(NewRoot(NewYaml("title: x"), NewParagraph(NewText("body"))), StringOptions{}))
+	assert.Equal(t, "x", ToString(NewText("x"), StringOptions{}))
+	assert.Equal(t, "alt", ToString(NewImage("a.png", "", "alt"), StringOptions{}))
+	assert.Equal(t, "", ToString(NewHTML("<br>"), StringOptions{}))
+	assert.Equal(t, "<br>", ToString(NewHTML("<br>"), StringOptions{IncludeHTML: true}))
+	assert.Equal(t, "a b", ToString(NewLink("u", "", NewText("a "), NewStrong(NewText("b"))), StringOptions{}))
+	// 空的块不产生多余的分隔符
+	list := NewList(false, NewListItem(), NewListItem(NewParagraph(NewText("a"))), NewListItem(NewThematicBreak()), NewListItem(NewParagraph(NewText("b"))))
+	assert.Equal(t, "a|b", ToString(list, StringOptions{BlockSeparator: "|"}))
+
+	// 空单元格保留分隔符，全空的行被省略
+	table := NewTable(nil,
+		NewTableRow(NewTableCell(), NewTableCell(NewText("b")), NewTableCell()),
+		NewTableRow(NewTableCell(), NewTableCell()),
+		NewTableRow(NewTableCell(NewText("c")), NewTableCell(), NewTableCell(NewText("d"))),
+	)
+	assert.Equal(t, "\tb\t\nc\t\td", ToString(table, StringOptions{}))
+}
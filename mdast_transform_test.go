@@ -0,0 +1,118 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transform 解析 src，执行 t 后重新输出 Markdown
+func transform(t *testing.T, src string, tr Transformer) string {
+	t.Helper()
+	root, err := Parse(context.Background(), []byte(src), WithGFM())
+	require.NoError(t, err)
+	require.NoError(t, tr.Transform(context.Background(), root))
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	return md
+}
+
+func TestLinksToReferences(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			"Shortcut and dedupe",
+			"See [Go](https://go.dev) and [Go](https://go.dev).\n",
+			"See [Go] and [Go].\n\n[Go]: https://go.dev\n",
+		},
+		{
+			"Same target different text",
+			"[Go](https://go.dev) or [golang](https://go.dev)\n",
+			"[Go] or [golang][go]\n\n[Go]: https://go.dev\n",
+		},
+		{
+			"Collapsed before parenthesis",
+			"[Go](https://go.dev)(1)\n",
+			"[Go][](1)\n\n[Go]: https://go.dev\n",
+		},
+		{
+			"Numbered label for formatted text",
+			"[*Go*](https://go.dev \"Home\")\n",
+			"[*Go*][1]\n\n[1]: https://go.dev \"Home\"\n",
+		},
+		{
+			"Label taken by another target",
+			"[Go](https://go.dev)\n\n[go]: https://example.com\n",
+			"[Go][1]\n\n[go]: https://example.com\n[1]: https://go.dev\n",
+		},
+		{
+			"Reuse existing definition",
+			"[Home](/index) and [x][home]\n\n[home]: /index\n",
+			"[Home] and [x][home]\n\n[home]: /index\n",
+		},
+		{
+			"Image",
+			"![logo](/logo.png) ![a_b*](/logo.png)\n",
+			"![logo] ![a_b\\*][logo]\n\n[logo]: /logo.png\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := transform(t, tc.src, LinksToReferences())
+			assert.Equal(t, tc.expected, md)
+
+			// 转换前后渲染出的 HTML 相同
+			assert.Equal(t, renderHTML(t, tc.src), renderHTML(t, md))
+		})
+	}
+}
+
+func TestReferencesToLinks(t *testing.T) {
+	src := "> [Go][] and ![logo][Logo] and [missing][nope]\n>\n> [go]: https://go.dev \"Home\"\n\n[logo]: /logo.png\n[LOGO]: /duplicate.png\n[unused]: /unused\n"
+	md := transform(t, src, ReferencesToLinks())
+	assert.Equal(t, "> [Go](https://go.dev \"Home\") and ![logo](/logo.png) and \\[missing\\]\\[nope\\]\n\n[unused]: /unused\n", md)
+
+	// 由 LinksToReferences 生成的引用可以还原为行内链接
+	inline := "[Go](https://go.dev) and [*Go*](https://go.dev)\n\n"
+	root, err := Parse(context.Background(), []byte(inline), WithGFM())
+	require.NoError(t, err)
+	require.NoError(t, NewProcessor().Use(LinksToReferences(), ReferencesToLinks()).Run(context.Background(), root))
+	md, err = root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, inline, md)
+}
+
+func TestNormalizeFootnotes(t *testing.T) {
+	src := "[^x]: Defined first.\n\nb[^B] a[^a] b again[^b] x[^x]\n\n[^a]: A with [^nested].\n\n[^b]: B\n\n[^spare]: Unused.\n\n[^nested]: Nested.\n\n[^b]: Duplicate.\n"
+	md := transform(t, src, NormalizeFootnotes())
+	assert.Equal(t, "b[^1] a[^2] b again[^1] x[^3]\n\n"+
+		"[^1]: B\n\n[^2]: A with [^4].\n\n[^3]: Defined first.\n\n[^4]: Nested.\n\n[^5]: Unused.\n\n", md)
+}
+
+func TestNormalizeFootnotesInline(t *testing.T) {
+	root := NewNode(NodeRoot)
+	para := NewNode(NodeParagraph)
+	para.AddPhrasingChild(textNode("see"))
+	para.AddPhrasingChild(createFootnoteNode("inline note"))
+	para.AddPhrasingChild(textNode(" and"))
+	para.AddPhrasingChild(createFootnoteReferenceNode("1"))
+	para.AddPhrasingChild(textNode(" and"))
+	para.AddPhrasingChild(createFootnoteReferenceNode("2"))
+	root.AddFlowChild(para)
+	root.AddFlowChild(createFootnoteDefinitionNode("2", "Second."))
+
+	require.NoError(t, NormalizeFootnotes().Transform(context.Background(), root))
+	md, err := root.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	// 找不到定义的 [^1] 保持不变，新编号跳过 1
+	assert.Equal(t, "see[^2] and[^1] and[^3]\n\n[^2]: inline note\n\n[^3]: Second.\n\n", md)
+
+	idx := BuildIndex(root)
+	require.Len(t, idx.Unresolved, 1)
+	assert.Empty(t, idx.Unused)
+}
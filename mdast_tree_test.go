@@ -0,0 +1,133 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func texts(nodes []*Node) []string {
+	var values []string
+	for _, n := range nodes {
+		values = append(values, n.Value)
+	}
+	return values
+}
+
+func TestTreeMutation(t *testing.T) {
+	a, b, c := NewText("a"), NewText("b"), NewText("c")
+	para := NewParagraph(a, c)
+	assert.Same(t, para, a.Parent())
+
+	require.NoError(t, c.InsertBefore(b))
+	assert.Equal(t, []string{"a", "b", "c"}, texts(para.Children()))
+	assert.Same(t, para, b.Parent())
+
+	d := NewText("d")
+	require.NoError(t, c.InsertAfter(d))
+	require.NoError(t, para.InsertChild(0, NewText("start")))
+	assert.Equal(t, []string{"start", "a", "b", "c", "d"}, texts(para.Children()))
+
+	require.NoError(t, b.Remove())
+	assert.Nil(t, b.Parent())
+	assert.Equal(t, []string{"start", "a", "c", "d"}, texts(para.Children()))
+	assert.Error(t, b.Remove())
+
+	x, y := NewText("x"), NewText("y")
+	require.NoError(t, c.ReplaceWith(x, y))
+	assert.Nil(t, c.Parent())
+	assert.Equal(t, []string{"start", "a", "x", "y", "d"}, texts(para.Children()))
+
+	// 插入已有父节点的节点等同于移动
+	require.NoError(t, a.InsertAfter(d))
+	assert.Equal(t, []string{"start", "a", "d", "x", "y"}, texts(para.Children()))
+	other := NewParagraph()
+	require.NoError(t, other.InsertChild(0, x))
+	assert.Same(t, other, x.Parent())
+	assert.Equal(t, []string{"start", "a", "d", "y"}, texts(para.Children()))
+
+	assert.Error(t, para.InsertChild(9, NewText("z")))
+	assert.Error(t, NewText("orphan").InsertBefore(NewText("z")))
+	assert.Error(t, y.ReplaceWith(y))
+	root := NewRoot(para)
+	assert.Error(t, a.InsertAfter(root), "cannot move an ancestor below itself")
+}
+
+func TestTreeMutationErrorsKeepTree(t *testing.T) {
+	a, b := NewText("a"), NewText("b")
+	para := NewParagraph(a, b)
+	root := NewRoot(para)
+	before := root.Clone()
+	unchanged := func() {
+		t.Helper()
+		eq, diff := Equal(before, root, EqualOptions{})
+		assert.True(t, eq, diff)
+		assert.Same(t, para, a.Parent())
+		assert.Same(t, para, b.Parent())
+	}
+
+	// 插入到自己旁边
+	assert.EqualError(t, a.InsertBefore(a), "cannot insert text node next to itself")
+	unchanged()
+	assert.EqualError(t, b.InsertAfter(a, b), "cannot insert text node next to itself")
+	unchanged()
+
+	// 后面的节点是插入位置的祖先时整个插入失败，前面的节点也不移动
+	em := NewEmphasis()
+	other := NewParagraph(em)
+	assert.EqualError(t, em.InsertBefore(b, other), "cannot insert paragraph node into its own subtree")
+	unchanged()
+	assert.Empty(t, em.PhrasingChildren)
+	assert.EqualError(t, em.InsertChild(0, other), "cannot insert paragraph node into its own subtree")
+	unchanged()
+
+	// nil 和重复的节点在修改树之前被拒绝
+	assert.EqualError(t, em.InsertBefore(b, nil), "cannot insert nil node")
+	unchanged()
+	assert.EqualError(t, em.InsertAfter(a, b, a), "cannot insert text node more than once")
+	unchanged()
+	assert.EqualError(t, em.ReplaceWith(b, b), "cannot insert text node more than once")
+	unchanged()
+	assert.Same(t, other, em.Parent())
+	assert.EqualError(t, para.InsertChild(0, nil), "cannot insert nil node")
+	unchanged()
+
+	// 下标越界时不移动 child
+	assert.EqualError(t, para.InsertChild(3, a), "index 3 out of range [0, 1] for paragraph children")
+	unchanged()
+	assert.EqualError(t, other.InsertChild(-1, b), "index -1 out of range [0, 1] for paragraph children")
+	unchanged()
+	// 同一切片内移动时按移出后的子节点数判断
+	require.NoError(t, para.InsertChild(1, a))
+	assert.Equal(t, []string{"b", "a"}, texts(para.Children()))
+}
+
+func TestTreeMutationKeepsSlots(t *testing.T) {
+	list := NewList(false, NewListItem(NewParagraph(NewText("one"))))
+	item := list.Children()[0]
+	require.NoError(t, item.InsertAfter(NewListItem(NewParagraph(NewText("two")))))
+	require.Len(t, list.ListChildren, 2)
+
+	row := NewTableRow(NewTableCell(NewText("a")))
+	require.NoError(t, row.InsertChild(1, NewTableCell(NewText("b"))))
+	require.Len(t, row.TableChildren, 2)
+
+	md, err := list.ToMarkdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "- one\n- two\n\n", md)
+}
+
+func TestFixParents(t *testing.T) {
+	text := &Node{Type: NodeText, Value: "x"}
+	para := &Node{Type: NodeParagraph, PhrasingChildren: []PhrasingContent{text}}
+	root := &Node{Type: NodeRoot, FlowChildren: []FlowContent{para}}
+	assert.Error(t, text.Remove())
+
+	FixParents(root)
+	assert.Nil(t, root.Parent())
+	assert.Same(t, para, text.Parent())
+	require.NoError(t, para.ReplaceWith(NewThematicBreak()))
+	assert.Equal(t, NodeThematicBreak, root.Children()[0].Type)
+}
@@ -0,0 +1,82 @@
+package mdast
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateValidTrees(t *testing.T) {
+	// 解析器输出的树都应当通过校验
+	for _, path := range []string{"testdata/commonmark_spec.json", "testdata/gfm_fixture.json"} {
+		for _, ex := range loadSpecExamples(t, path) {
+			root, err := Parse(context.Background(), []byte(ex.Markdown), WithGFM())
+			require.NoError(t, err)
+			assert.Empty(t, Validate(root), "example %d: %q", ex.Example, ex.Markdown)
+		}
+	}
+
+	root := NewRoot(
+		NewHeading(1, NewText("x")),
+		NewList(false, NewTaskListItem(true, NewParagraph(NewLinkReference("a", ReferenceFull, NewText("a"))))),
+		NewTable([]AlignType{AlignLeft}, NewTableRow(NewTableCell(NewFootnote(NewText("note"))))),
+		NewDefinition("a", "/a", ""),
+	)
+	assert.Empty(t, Validate(root))
+}
+
+func TestValidateErrors(t *testing.T) {
+	cell := NewTableCell()
+	cell.PhrasingChildren = append(cell.PhrasingChildren, &Node{Type: NodeHeading, Data: DataTable{NDK_Depth: 2}})
+	list := NewList(false)
+	list.AddListChild(NewText("loose text"))
+	para := NewParagraph(&Node{Type: NodeLink}, NewText("x"))
+	para.FlowChildren = append(para.FlowChildren, NewParagraph())
+
+	root := NewRoot(
+		&Node{Type: NodeHeading, Data: DataTable{NDK_Depth: 9}},
+		NewTable(nil, NewTableRow(cell)),
+		list,
+		para,
+		&Node{Type: NodeLinkReference, Data: DataTable{NDK_Identifier: "a", NDK_ReferenceType: "odd"}},
+		&Node{Type: "custom"},
+		&Node{Type: NodeText, Value: "x", FlowChildren: []FlowContent{NewText("y")}},
+	)
+
+	var got []string
+	for _, err := range Validate(root) {
+		got = append(got, err.Error())
+	}
+	assert.Equal(t, []string{
+		"root > heading[0]: invalid depth 9 for heading, expected 1 to 6",
+		"root > table[1] > tableRow[0] > tableCell[0] > heading[0]: heading is not phrasing content and cannot be a child of tableCell",
+		"root > list[2] > text[0]: text is not list content and cannot be a child of list",
+		"root > paragraph[3]: children of paragraph must be stored in PhrasingChildren, found 1 in FlowChildren",
+		"root > paragraph[3] > paragraph[0]: paragraph is not phrasing content and cannot be a child of paragraph",
+		"root > paragraph[3] > link[1]: missing or invalid url for link",
+		"root > linkReference[4]: missing or invalid referenceType for linkReference",
+		"root > custom[5]: unknown node type \"custom\"",
+		"root > text[6]: text cannot have children",
+	}, got)
+}
+
+func TestValidateNilRoot(t *testing.T) {
+	errs := Validate(nil)
+	require.Len(t, errs, 1)
+	assert.Nil(t, errs[0].Node)
+	assert.Equal(t, "<nil>: root is nil", errs[0].Error())
+}
+
+func TestValidateErrorPosition(t *testing.T) {
+	root, err := Parse(context.Background(), []byte("# Title\n"))
+	require.NoError(t, err)
+	heading := root.FlowChildren[0].(*Node)
+	heading.SetData(NDK_Depth, 0)
+
+	errs := Validate(root)
+	require.Len(t, errs, 1)
+	assert.Same(t, heading, errs[0].Node)
+	assert.Equal(t, "root > heading[0] (1:1-1:8): invalid depth 0 for heading, expected 1 to 6", errs[0].Error())
+}
@@ -0,0 +1,102 @@
+package mdast
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseForWalk(t *testing.T) *Node {
+	t.Helper()
+	root, err := Parse(context.Background(), []byte("# A *b*\n\n- one\n- two [x](/x)\n\n| c |\n|---|\n| d |\n"), WithGFM())
+	require.NoError(t, err)
+	return root
+}
+
+func TestWalkEnterLeaveOrder(t *testing.T) {
+	root := parseForWalk(t)
+	var events []string
+	Walk(root, Visitor{
+		Enter: func(n *Node, ancestors []*Node) VisitAction {
+			events = append(events, "+"+string(n.Type))
+			return Continue
+		},
+		Leave: func(n *Node, ancestors []*Node) VisitAction {
+			events = append(events, "-"+string(n.Type))
+			return Continue
+		},
+	})
+	assert.Equal(t, strings.Fields(`+root
+		+heading +text -text +emphasis +text -text -emphasis -heading
+		+list
+			+listItem +paragraph +text -text -paragraph -listItem
+			+listItem +paragraph +text -text +link +text -text -link -paragraph -listItem
+		-list
+		+table
+			+tableRow +tableCell +text -text -tableCell -tableRow
+			+tableRow +tableCell +text -text -tableCell -tableRow
+		-table
+		-root`), events)
+}
+
+func TestWalkAncestors(t *testing.T) {
+	root := parseForWalk(t)
+	var path []NodeType
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		for _, a := range ancestors {
+			path = append(path, a.Type)
+		}
+		return Stop
+	}, NodeLink)
+	assert.Equal(t, []NodeType{NodeRoot, NodeList, NodeListItem, NodeParagraph}, path)
+}
+
+func TestWalkSkipChildrenAndStop(t *testing.T) {
+	root := parseForWalk(t)
+
+	var texts []string
+	Walk(root, Visitor{
+		Enter: func(n *Node, ancestors []*Node) VisitAction {
+			switch n.Type {
+			case NodeList, NodeTable:
+				return SkipChildren
+			case NodeText:
+				texts = append(texts, n.Value)
+			}
+			return Continue
+		},
+	})
+	assert.Equal(t, []string{"A ", "b"}, texts)
+
+	var left []NodeType
+	Walk(root, Visitor{
+		Leave: func(n *Node, ancestors []*Node) VisitAction {
+			left = append(left, n.Type)
+			if n.Type == NodeHeading {
+				return Stop
+			}
+			return Continue
+		},
+	})
+	assert.Equal(t, []NodeType{NodeText, NodeText, NodeEmphasis, NodeHeading}, left)
+}
+
+func TestVisitTypeFilter(t *testing.T) {
+	root := parseForWalk(t)
+	var values []string
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		values = append(values, n.Value)
+		return Continue
+	}, NodeText)
+	assert.Equal(t, []string{"A ", "b", "one", "two ", "x", "c", "d"}, values)
+
+	count := 0
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		count++
+		return Continue
+	}, NodeListItem, NodeTableCell)
+	assert.Equal(t, 4, count)
+}
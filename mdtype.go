@@ -4,16 +4,19 @@ import "context"
 
 // Position 表示节点在源文件中的位置
 type Position struct {
-	Start  Point
-	End    Point
-	Indent []int
+	Start  Point `json:"start"`
+	End    Point `json:"end"`
+	Indent []int `json:"indent,omitempty"`
 }
 
 // Point 表示位置的具体点
 type Point struct {
-	Line   int
-	Column int
-	Offset int
+	// Line 是从 1 开始的行号
+	Line int `json:"line"`
+	// Column 是从 1 开始的列号，按 Unicode 码位计数
+	Column int `json:"column"`
+	// Offset 是从 0 开始、相对源文本开头的字节偏移
+	Offset int `json:"offset"`
 }
 
 // NodeType 表示 Markdown AST 节点的类型
@@ -78,7 +81,9 @@ type Node struct {
 	ListChildren     []ListContent
 	TableChildren    []TableContent
 	Data             DataTable
-	parent           *Node
+	// Position 是节点在源文本中的位置，仅由 Parse 填充
+	Position *Position
+	parent   *Node
 }
 
 // IsBlock 检查节点是否为块级元素
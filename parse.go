@@ -0,0 +1,228 @@
+package mdast
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var reLineEnding = regexp.MustCompile(`\r\n|\n|\r`)
+
+// ParseOptions 控制 Parse 的解析行为
+type ParseOptions struct {
+	// GFM 开启 GitHub Flavored Markdown 扩展：表格、删除线、任务列表、扩展自动链接和脚注
+	GFM bool
+}
+
+// ParseOption 用于设置 ParseOptions
+type ParseOption func(*ParseOptions)
+
+// WithGFM 开启 GitHub Flavored Markdown 解析模式
+func WithGFM() ParseOption {
+	return func(o *ParseOptions) {
+		o.GFM = true
+	}
+}
+
+// Parse 将 CommonMark 文本解析为以 NodeRoot 为根的语法树，可通过 WithGFM 开启 GFM 扩展
+func Parse(ctx context.Context, src []byte, opts ...ParseOption) (*Node, error) {
+	var options ParseOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	input := string(src)
+	lines := reLineEnding.Split(input, -1)
+	if strings.HasSuffix(input, "\n") || strings.HasSuffix(input, "\r") {
+		lines = lines[:len(lines)-1]
+	}
+
+	lineStarts := []int{0}
+	for _, loc := range reLineEnding.FindAllStringIndex(input, -1) {
+		lineStarts = append(lineStarts, loc[1])
+	}
+
+	inline := newInlineParser(options.GFM)
+	bp := newBlockParser(inline, options.GFM, lineStarts)
+	for _, line := range lines {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bp.incorporateLine(line)
+	}
+	for bp.tip != nil {
+		bp.finalize(bp.tip, len(lines))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c := &converter{inline: inline, lineStarts: lineStarts}
+	root := NewNode(NodeRoot)
+	for _, child := range c.convertBlocks(bp.doc.children) {
+		root.AddFlowChild(child)
+	}
+	root.Position = &Position{
+		Start: Point{Line: 1, Column: 1, Offset: 0},
+		End:   Point{Line: len(lineStarts), Column: 1, Offset: len(input)},
+	}
+	if last := lineStarts[len(lineStarts)-1]; last < len(input) {
+		root.Position.End = Point{Line: len(lineStarts), Column: len(input) - last + 1, Offset: len(input)}
+	}
+	c.runeColumns(root, input)
+	return root, nil
+}
+
+// runeColumns 将树中各位置的列号改为按码位计算，解析过程中的列号按字节计算
+func (c *converter) runeColumns(n *Node, input string) {
+	if n.Position != nil {
+		n.Position.Start = c.runeColumn(n.Position.Start, input)
+		n.Position.End = c.runeColumn(n.Position.End, input)
+	}
+	for _, child := range n.Children() {
+		c.runeColumns(child, input)
+	}
+}
+
+func (c *converter) runeColumn(p Point, input string) Point {
+	if p.Line < 1 || p.Line > len(c.lineStarts) {
+		return p
+	}
+	start := c.lineStarts[p.Line-1]
+	if p.Offset < start || p.Offset > len(input) {
+		return p
+	}
+	p.Column = utf8.RuneCountInString(input[start:p.Offset]) + 1
+	return p
+}
+
+// converter 将块解析得到的中间结构转换为语法树节点
+type converter struct {
+	inline     *inlineParser
+	lineStarts []int
+}
+
+// blockPosition 根据块的起止行列计算其源位置
+func (c *converter) blockPosition(b *block) *Position {
+	if b.startLine < 1 || b.startLine > len(c.lineStarts) {
+		return nil
+	}
+	start := Point{Line: b.startLine, Column: b.startColumn, Offset: c.lineStarts[b.startLine-1] + b.startColumn - 1}
+	end := start
+	if b.endLine >= b.startLine && b.endLine <= len(c.lineStarts) {
+		end = Point{Line: b.endLine, Column: b.endColumn + 1, Offset: c.lineStarts[b.endLine-1] + b.endColumn}
+	}
+	return &Position{Start: start, End: end}
+}
+
+// convertBlocks 将块解析得到的中间结构转换为流式内容节点
+func (c *converter) convertBlocks(blocks []*block) []FlowContent {
+	result := []FlowContent{}
+	for _, b := range blocks {
+		for _, def := range b.defs {
+			result = append(result, def)
+		}
+		if n := c.convertBlock(b); n != nil {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+func (c *converter) convertBlock(b *block) *Node {
+	inline := c.inline
+	var n *Node
+	switch b.kind {
+	case blockQuote:
+		n = NewNode(NodeBlockquote)
+		for _, child := range c.convertBlocks(b.children) {
+			n.AddFlowChild(child)
+		}
+	case blockList:
+		n = NewNode(NodeList)
+		n.SetData(NDK_Ordered, b.list.ordered)
+		if b.list.ordered {
+			n.SetData(NDK_Start, b.list.start)
+		}
+		n.SetData(NDK_Spread, !b.list.tight)
+		for _, item := range b.children {
+			n.AddListChild(c.convertBlock(item))
+		}
+	case blockItem:
+		n = NewNode(NodeListItem)
+		n.SetData(NDK_Spread, itemHasBlankGap(b))
+		if first := firstChild(b); inline.gfm && first != nil && first.kind == blockParagraph {
+			content := first.content.String()
+			if checked, rest, ok := taskListMarker(content); ok {
+				n.SetData(NDK_Checked, checked)
+				first.content.Reset()
+				first.content.WriteString(rest)
+				first.contentOffset += len(content) - len(rest)
+			}
+		}
+		for _, child := range c.convertBlocks(b.children) {
+			n.AddFlowChild(child)
+		}
+	case blockHeading:
+		n = NewNode(NodeHeading)
+		n.SetData(NDK_Depth, b.level)
+		for _, child := range inline.parse(b.content.String(), b.sourceMap()) {
+			n.AddPhrasingChild(child)
+		}
+	case blockThematicBreak:
+		n = NewNode(NodeThematicBreak)
+	case blockCode:
+		n = NewNode(NodeCode)
+		n.Value = strings.TrimSuffix(b.literal, "\n")
+		if b.info != "" {
+			lang, meta := b.info, ""
+			if i := strings.IndexAny(b.info, " \t"); i >= 0 {
+				lang, meta = b.info[:i], strings.TrimLeft(b.info[i:], " \t")
+			}
+			n.SetData(NDK_Lang, lang)
+			if meta != "" {
+				n.SetData(NDK_Meta, meta)
+			}
+		}
+	case blockHTML:
+		n = NewNode(NodeHTML)
+		n.Value = b.literal
+	case blockParagraph:
+		content := b.content.String()
+		if isBlankLine(content) {
+			// 段落只包含链接定义时，定义已经在 convertBlocks 中输出
+			return nil
+		}
+		n = NewNode(NodeParagraph)
+		for _, child := range inline.parse(content, b.sourceMap()) {
+			n.AddPhrasingChild(child)
+		}
+		if pos := c.blockPosition(b); pos != nil {
+			// 链接定义已从段落开头剥离，段落从剩余内容开始
+			pos.Start = b.sourceMap().point(0)
+			n.Position = pos
+		}
+		return n
+	case blockTable:
+		n = c.convertTable(b)
+	case blockFootnote:
+		n = NewNode(NodeFootnoteDefinition)
+		n.SetData(NDK_Identifier, normalizeLabel(b.label))
+		n.SetData(NDK_Label, b.label)
+		for _, child := range c.convertBlocks(b.children) {
+			n.AddFlowChild(child)
+		}
+	default:
+		return nil
+	}
+	n.Position = c.blockPosition(b)
+	return n
+}
+
+func firstChild(b *block) *block {
+	if len(b.children) == 0 {
+		return nil
+	}
+	return b.children[0]
+}
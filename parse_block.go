@@ -0,0 +1,776 @@
+package mdast
+
+import (
+	"regexp"
+	"strings"
+)
+
+// blockKind 表示块解析阶段的内部块类型
+type blockKind int
+
+const (
+	blockDocument blockKind = iota
+	blockQuote
+	blockList
+	blockItem
+	blockHeading
+	blockThematicBreak
+	blockCode
+	blockHTML
+	blockParagraph
+	blockTable
+	blockFootnote
+)
+
+const codeIndent = 4
+
+var (
+	reATXHeadingMarker = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+	reATXClosingEmpty  = regexp.MustCompile(`^[ \t]*#+[ \t]*$`)
+	reATXClosing       = regexp.MustCompile(`[ \t]+#+[ \t]*$`)
+	reClosingCodeFence = regexp.MustCompile("^(?:`{3,}|~{3,})[ \t]*$")
+	reSetextHeading    = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+	reThematicBreak    = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:_[ \t]*){3,}|(?:-[ \t]*){3,})$`)
+	reOrderedListStart = regexp.MustCompile(`^(\d{1,9})([.)])`)
+
+	reHTMLBlockOpen = [...]*regexp.Regexp{
+		nil,
+		regexp.MustCompile(`(?i)^<(?:script|pre|textarea|style)(?:\s|>|$)`),
+		regexp.MustCompile(`^<!--`),
+		regexp.MustCompile(`^<[?]`),
+		regexp.MustCompile(`^<![A-Za-z]`),
+		regexp.MustCompile(`^<!\[CDATA\[`),
+		regexp.MustCompile(`(?i)^</?(?:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[123456]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:\s|/?>|$)`),
+		regexp.MustCompile(`(?i)^(?:` + reOpenTag + `|` + reCloseTag + `)\s*$`),
+	}
+	reHTMLBlockClose = [...]*regexp.Regexp{
+		nil,
+		regexp.MustCompile(`(?i)</(?:script|pre|textarea|style)>`),
+		regexp.MustCompile(`-->`),
+		regexp.MustCompile(`\?>`),
+		regexp.MustCompile(`>`),
+		regexp.MustCompile(`\]\]>`),
+	}
+)
+
+// listData 记录列表及列表项的标记信息
+type listData struct {
+	ordered      bool
+	bulletChar   byte
+	start        int
+	delimiter    byte
+	padding      int
+	markerOffset int
+	tight        bool
+}
+
+// block 是块解析阶段使用的中间结构，解析完成后会被转换为 Node
+type block struct {
+	kind     blockKind
+	parent   *block
+	children []*block
+	open     bool
+
+	content strings.Builder
+	literal string
+
+	list  *listData
+	level int
+
+	fenced      bool
+	fenceChar   byte
+	fenceLength int
+	fenceOffset int
+	info        string
+
+	htmlType int
+
+	// align 与 rows 记录 GFM 表格的对齐方式和各行的原始单元格
+	align []AlignType
+	rows  [][]tableCell
+
+	// label 是 GFM 脚注定义的原始标签
+	label string
+
+	// defs 是从段落开头剥离出来的链接定义，转换时放在该块之前
+	defs []*Node
+
+	// segments 记录 content 中每一行对应的源文本位置，contentOffset 是已从 content 开头剥离的字节数
+	segments      []lineSegment
+	contentOffset int
+	// rowPositions 记录 GFM 表格表头及各数据行在源文本中的位置
+	rowPositions []Position
+
+	startLine, startColumn int
+	endLine, endColumn     int
+}
+
+func (b *block) lastChild() *block {
+	if len(b.children) == 0 {
+		return nil
+	}
+	return b.children[len(b.children)-1]
+}
+
+// acceptsLines 判断块是否直接接收文本行
+func (b *block) acceptsLines() bool {
+	return b.kind == blockParagraph || b.kind == blockCode || b.kind == blockHTML
+}
+
+// canContain 判断块是否可以包含指定类型的子块
+func (b *block) canContain(kind blockKind) bool {
+	switch b.kind {
+	case blockDocument, blockQuote, blockItem, blockFootnote:
+		return kind != blockItem
+	case blockList:
+		return kind == blockItem
+	default:
+		return false
+	}
+}
+
+// blockParser 实现 CommonMark 的块结构解析
+type blockParser struct {
+	doc                  *block
+	tip                  *block
+	oldtip               *block
+	lastMatchedContainer *block
+
+	line       string
+	lineNumber int
+
+	offset               int
+	column               int
+	nextNonspace         int
+	nextNonspaceColumn   int
+	indent               int
+	indented             bool
+	blank                bool
+	partiallyConsumedTab bool
+	allClosed            bool
+	lastLineLength       int
+
+	// lineStarts 是每一行在源文本中的起始偏移
+	lineStarts []int
+
+	inline *inlineParser
+	gfm    bool
+}
+
+func newBlockParser(inline *inlineParser, gfm bool, lineStarts []int) *blockParser {
+	doc := &block{kind: blockDocument, open: true, startLine: 1, startColumn: 1}
+	return &blockParser{doc: doc, tip: doc, oldtip: doc, inline: inline, gfm: gfm, lineStarts: lineStarts}
+}
+
+// pointAt 返回当前行中字节偏移 offset 处的源文本位置
+func (p *blockParser) pointAt(offset int) Point {
+	return Point{Line: p.lineNumber, Column: offset + 1, Offset: p.lineStarts[p.lineNumber-1] + offset}
+}
+
+// sourceMap 返回块当前内容的位置映射
+func (b *block) sourceMap() sourceMap {
+	return sourceMap{segments: b.segments, shift: b.contentOffset}
+}
+
+func (p *blockParser) peek(i int) int {
+	if i < len(p.line) {
+		return int(p.line[i])
+	}
+	return -1
+}
+
+func (p *blockParser) findNextNonspace() {
+	i, cols := p.offset, p.column
+	for i < len(p.line) {
+		c := p.line[i]
+		if c == ' ' {
+			i++
+			cols++
+		} else if c == '\t' {
+			i++
+			cols += 4 - cols%4
+		} else {
+			break
+		}
+	}
+	p.blank = i >= len(p.line) || p.line[i] == '\n' || p.line[i] == '\r'
+	p.nextNonspace = i
+	p.nextNonspaceColumn = cols
+	p.indent = cols - p.column
+	p.indented = p.indent >= codeIndent
+}
+
+func (p *blockParser) advanceNextNonspace() {
+	p.offset = p.nextNonspace
+	p.column = p.nextNonspaceColumn
+	p.partiallyConsumedTab = false
+}
+
+// advanceOffset 前进 count 个字符，columns 为 true 时按列计算（制表符可能被部分消费）
+func (p *blockParser) advanceOffset(count int, columns bool) {
+	for count > 0 && p.offset < len(p.line) {
+		if p.line[p.offset] == '\t' {
+			charsToTab := 4 - p.column%4
+			if columns {
+				p.partiallyConsumedTab = charsToTab > count
+				advance := min(charsToTab, count)
+				p.column += advance
+				if !p.partiallyConsumedTab {
+					p.offset++
+				}
+				count -= advance
+			} else {
+				p.partiallyConsumedTab = false
+				p.column += charsToTab
+				p.offset++
+				count--
+			}
+		} else {
+			p.partiallyConsumedTab = false
+			p.offset++
+			p.column++
+			count--
+		}
+	}
+}
+
+func (p *blockParser) addLine() {
+	if p.partiallyConsumedTab {
+		p.tip.segments = append(p.tip.segments, lineSegment{start: p.tip.content.Len(), point: p.pointAt(p.offset)})
+		p.offset++
+		p.tip.content.WriteString(strings.Repeat(" ", 4-p.column%4))
+	}
+	p.tip.segments = append(p.tip.segments, lineSegment{start: p.tip.content.Len(), point: p.pointAt(p.offset)})
+	p.tip.content.WriteString(p.line[p.offset:])
+	p.tip.content.WriteByte('\n')
+}
+
+func (p *blockParser) addChild(kind blockKind, offset int) *block {
+	for !p.tip.canContain(kind) {
+		p.finalize(p.tip, p.lineNumber-1)
+	}
+	b := &block{kind: kind, parent: p.tip, open: true, startLine: p.lineNumber, startColumn: offset + 1}
+	p.tip.children = append(p.tip.children, b)
+	p.tip = b
+	return b
+}
+
+func (p *blockParser) closeUnmatchedBlocks() {
+	if p.allClosed {
+		return
+	}
+	for p.oldtip != p.lastMatchedContainer {
+		parent := p.oldtip.parent
+		p.finalize(p.oldtip, p.lineNumber-1)
+		p.oldtip = parent
+	}
+	p.allClosed = true
+}
+
+// continueBlock 判断已打开的块能否延续到当前行：0 表示匹配，1 表示不匹配，2 表示该行已被完全消费
+func (p *blockParser) continueBlock(b *block) int {
+	switch b.kind {
+	case blockDocument, blockList:
+		return 0
+	case blockQuote:
+		if !p.indented && p.peek(p.nextNonspace) == '>' {
+			p.advanceNextNonspace()
+			p.advanceOffset(1, false)
+			if c := p.peek(p.offset); c == ' ' || c == '\t' {
+				p.advanceOffset(1, true)
+			}
+			return 0
+		}
+		return 1
+	case blockItem:
+		if p.blank {
+			if len(b.children) == 0 {
+				// 空列表项之后的空行会结束该列表项
+				return 1
+			}
+			p.advanceNextNonspace()
+		} else if p.indent >= b.list.markerOffset+b.list.padding {
+			p.advanceOffset(b.list.markerOffset+b.list.padding, true)
+		} else {
+			return 1
+		}
+		return 0
+	case blockHeading, blockThematicBreak:
+		return 1
+	case blockCode:
+		if b.fenced {
+			rest := p.line[p.nextNonspace:]
+			if p.indent <= 3 && len(rest) > 0 && rest[0] == b.fenceChar && reClosingCodeFence.MatchString(rest) {
+				if n := countLeading(rest, b.fenceChar); n >= b.fenceLength {
+					p.lastLineLength = p.offset + p.indent + n
+					p.finalize(b, p.lineNumber)
+					return 2
+				}
+			}
+			for i := b.fenceOffset; i > 0; i-- {
+				if c := p.peek(p.offset); c != ' ' && c != '\t' {
+					break
+				}
+				p.advanceOffset(1, true)
+			}
+			return 0
+		}
+		if p.indent >= codeIndent {
+			p.advanceOffset(codeIndent, true)
+		} else if p.blank {
+			p.advanceNextNonspace()
+		} else {
+			return 1
+		}
+		return 0
+	case blockHTML:
+		if p.blank && (b.htmlType == 6 || b.htmlType == 7) {
+			return 1
+		}
+		return 0
+	case blockParagraph, blockTable:
+		if p.blank {
+			return 1
+		}
+		return 0
+	case blockFootnote:
+		if p.indent >= codeIndent {
+			p.advanceOffset(codeIndent, true)
+		} else if p.blank {
+			p.advanceNextNonspace()
+		} else {
+			return 1
+		}
+		return 0
+	}
+	return 1
+}
+
+// finalize 关闭一个块，并完成该块类型需要的收尾处理
+func (p *blockParser) finalize(b *block, lineNumber int) {
+	above := b.parent
+	b.open = false
+	b.endLine = lineNumber
+	b.endColumn = p.lastLineLength
+
+	switch b.kind {
+	case blockParagraph:
+		content := p.extractDefinitions(b, b.content.String())
+		b.content.Reset()
+		b.content.WriteString(content)
+	case blockCode:
+		content := b.content.String()
+		if b.fenced {
+			firstLine, rest, _ := strings.Cut(content, "\n")
+			b.info = unescapeString(trimMarkdownSpace(firstLine))
+			b.literal = rest
+		} else {
+			lines := strings.Split(content, "\n")
+			for len(lines) > 0 && isBlankLine(lines[len(lines)-1]) {
+				lines = lines[:len(lines)-1]
+			}
+			b.literal = strings.Join(lines, "\n") + "\n"
+			b.endLine = b.startLine + len(lines) - 1
+			if len(lines) > 0 {
+				b.endColumn = b.startColumn + len(lines[len(lines)-1]) - 1
+			}
+		}
+		b.content.Reset()
+	case blockHTML:
+		b.literal = strings.TrimSuffix(b.content.String(), "\n")
+		b.content.Reset()
+	case blockList:
+		b.list.tight = true
+		for i, item := range b.children {
+			if i < len(b.children)-1 && endsWithBlankLine(item, b.children[i+1]) {
+				b.list.tight = false
+				break
+			}
+			if itemHasBlankGap(item) {
+				b.list.tight = false
+				break
+			}
+		}
+		if last := b.lastChild(); last != nil {
+			b.endLine, b.endColumn = last.endLine, last.endColumn
+		}
+	case blockItem:
+		if last := b.lastChild(); last != nil {
+			b.endLine, b.endColumn = last.endLine, last.endColumn
+		} else {
+			b.endLine = b.startLine
+			b.endColumn = b.list.padding + b.list.markerOffset
+		}
+	case blockTable:
+		content := b.content.String()
+		for _, seg := range b.segments {
+			if seg.start > 0 && content[seg.start-1] != '\n' {
+				continue
+			}
+			line, _, _ := strings.Cut(content[seg.start:], "\n")
+			b.rows = append(b.rows, splitTableRow(line))
+			b.rowPositions = append(b.rowPositions, linePosition(seg.point, len(line)))
+		}
+		b.content.Reset()
+	case blockFootnote:
+		if last := b.lastChild(); last != nil {
+			b.endLine, b.endColumn = last.endLine, last.endColumn
+		}
+	}
+	p.tip = above
+}
+
+// endsWithBlankLine 判断 b 与其后继兄弟块之间是否隔有空行
+func endsWithBlankLine(b, next *block) bool {
+	return b.endLine != next.startLine-1
+}
+
+// itemHasBlankGap 判断列表项的直接子块之间是否隔有空行
+func itemHasBlankGap(item *block) bool {
+	children := item.children
+	for i := 0; i+1 < len(children); i++ {
+		if endsWithBlankLine(children[i], children[i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractDefinitions 从段落开头解析链接引用定义，返回剩余内容
+func (p *blockParser) extractDefinitions(b *block, content string) string {
+	for len(content) > 0 && content[0] == '[' {
+		def, n := p.inline.parseReference(content)
+		if n == 0 {
+			break
+		}
+		sm := b.sourceMap()
+		def.Position = &Position{Start: sm.point(0), End: sm.point(len(strings.TrimRight(content[:n], " \t\n")))}
+		b.defs = append(b.defs, def)
+		b.contentOffset += n
+		content = content[n:]
+	}
+	return content
+}
+
+func countLeading(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+// blockStart 尝试在当前行开启一个新块：0 表示未匹配，1 表示开启了容器块，2 表示开启了叶子块
+type blockStart func(p *blockParser, container *block) int
+
+var blockStarts = []blockStart{
+	startBlockquote,
+	startATXHeading,
+	startFencedCode,
+	startHTMLBlock,
+	startSetextHeading,
+	startThematicBreak,
+	startListItem,
+	startIndentedCode,
+	startTable,
+	startFootnoteDefinition,
+}
+
+func startBlockquote(p *blockParser, _ *block) int {
+	if p.indented || p.peek(p.nextNonspace) != '>' {
+		return 0
+	}
+	p.advanceNextNonspace()
+	p.advanceOffset(1, false)
+	if c := p.peek(p.offset); c == ' ' || c == '\t' {
+		p.advanceOffset(1, true)
+	}
+	p.closeUnmatchedBlocks()
+	p.addChild(blockQuote, p.nextNonspace)
+	return 1
+}
+
+func startATXHeading(p *blockParser, _ *block) int {
+	if p.indented {
+		return 0
+	}
+	m := reATXHeadingMarker.FindString(p.line[p.nextNonspace:])
+	if m == "" {
+		return 0
+	}
+	p.advanceNextNonspace()
+	p.advanceOffset(len(m), false)
+	p.closeUnmatchedBlocks()
+	b := p.addChild(blockHeading, p.nextNonspace)
+	b.level = len(strings.TrimRight(m, " \t"))
+	content := p.line[p.offset:]
+	if reATXClosingEmpty.MatchString(content) {
+		content = ""
+	} else {
+		content = reATXClosing.ReplaceAllString(content, "")
+	}
+	b.segments = []lineSegment{{start: 0, point: p.pointAt(p.offset)}}
+	b.content.WriteString(content)
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return 2
+}
+
+func startFencedCode(p *blockParser, _ *block) int {
+	if p.indented {
+		return 0
+	}
+	rest := p.line[p.nextNonspace:]
+	if len(rest) < 3 || (rest[0] != '`' && rest[0] != '~') {
+		return 0
+	}
+	fenceChar := rest[0]
+	n := countLeading(rest, fenceChar)
+	if n < 3 || (fenceChar == '`' && strings.IndexByte(rest[n:], '`') >= 0) {
+		return 0
+	}
+	p.closeUnmatchedBlocks()
+	b := p.addChild(blockCode, p.nextNonspace)
+	b.fenced = true
+	b.fenceLength = n
+	b.fenceChar = fenceChar
+	b.fenceOffset = p.indent
+	p.advanceNextNonspace()
+	p.advanceOffset(n, false)
+	return 2
+}
+
+func startHTMLBlock(p *blockParser, container *block) int {
+	if p.indented || p.peek(p.nextNonspace) != '<' {
+		return 0
+	}
+	s := p.line[p.nextNonspace:]
+	for t := 1; t <= 7; t++ {
+		if !reHTMLBlockOpen[t].MatchString(s) {
+			continue
+		}
+		// 第 7 类 HTML 块不能打断段落（包括惰性延续的段落）
+		if t == 7 && (container.kind == blockParagraph ||
+			(!p.allClosed && !p.blank && p.tip.kind == blockParagraph)) {
+			continue
+		}
+		p.closeUnmatchedBlocks()
+		b := p.addChild(blockHTML, p.offset)
+		b.htmlType = t
+		return 2
+	}
+	return 0
+}
+
+func startSetextHeading(p *blockParser, container *block) int {
+	if p.indented || container.kind != blockParagraph {
+		return 0
+	}
+	m := reSetextHeading.FindString(p.line[p.nextNonspace:])
+	if m == "" {
+		return 0
+	}
+	p.closeUnmatchedBlocks()
+	content := p.extractDefinitions(container, container.content.String())
+	container.content.Reset()
+	container.content.WriteString(content)
+	if content == "" {
+		return 0
+	}
+	container.kind = blockHeading
+	if m[0] == '=' {
+		container.level = 1
+	} else {
+		container.level = 2
+	}
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return 2
+}
+
+func startThematicBreak(p *blockParser, _ *block) int {
+	if p.indented || !reThematicBreak.MatchString(p.line[p.nextNonspace:]) {
+		return 0
+	}
+	p.closeUnmatchedBlocks()
+	p.addChild(blockThematicBreak, p.nextNonspace)
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return 2
+}
+
+func startListItem(p *blockParser, container *block) int {
+	if p.indented && container.kind != blockList {
+		return 0
+	}
+	data := p.parseListMarker(container)
+	if data == nil {
+		return 0
+	}
+	p.closeUnmatchedBlocks()
+	if p.tip.kind != blockList || !listsMatch(p.tip.list, data) {
+		list := p.addChild(blockList, p.nextNonspace)
+		list.list = data
+	}
+	item := p.addChild(blockItem, p.nextNonspace)
+	item.list = data
+	return 1
+}
+
+func startIndentedCode(p *blockParser, _ *block) int {
+	if !p.indented || p.tip.kind == blockParagraph || p.blank {
+		return 0
+	}
+	p.advanceOffset(codeIndent, true)
+	p.closeUnmatchedBlocks()
+	p.addChild(blockCode, p.offset)
+	return 2
+}
+
+// parseListMarker 解析列表标记，返回 nil 表示当前行不是列表项
+func (p *blockParser) parseListMarker(container *block) *listData {
+	if p.indent >= codeIndent {
+		return nil
+	}
+	rest := p.line[p.nextNonspace:]
+	data := &listData{markerOffset: p.indent, tight: true}
+	markerLen := 0
+	if len(rest) > 0 && (rest[0] == '*' || rest[0] == '+' || rest[0] == '-') {
+		data.bulletChar = rest[0]
+		markerLen = 1
+	} else if m := reOrderedListStart.FindStringSubmatch(rest); m != nil &&
+		(container.kind != blockParagraph || m[1] == "1") {
+		data.ordered = true
+		data.start = atoi(m[1])
+		data.delimiter = m[2][0]
+		markerLen = len(m[0])
+	} else {
+		return nil
+	}
+
+	if c := p.peek(p.nextNonspace + markerLen); c != -1 && c != '\t' && c != ' ' {
+		return nil
+	}
+	// 打断段落的列表项第一行不能为空
+	if container.kind == blockParagraph && isBlankLine(p.line[p.nextNonspace+markerLen:]) {
+		return nil
+	}
+
+	p.advanceNextNonspace()
+	p.advanceOffset(markerLen, true)
+	spacesStartColumn := p.column
+	spacesStartOffset := p.offset
+	for {
+		p.advanceOffset(1, true)
+		c := p.peek(p.offset)
+		if p.column-spacesStartColumn >= 5 || (c != ' ' && c != '\t') {
+			break
+		}
+	}
+	blankItem := p.peek(p.offset) == -1
+	spacesAfterMarker := p.column - spacesStartColumn
+	if spacesAfterMarker >= 5 || spacesAfterMarker < 1 || blankItem {
+		data.padding = markerLen + 1
+		p.column = spacesStartColumn
+		p.offset = spacesStartOffset
+		if c := p.peek(p.offset); c == ' ' || c == '\t' {
+			p.advanceOffset(1, true)
+		}
+	} else {
+		data.padding = markerLen + spacesAfterMarker
+	}
+	return data
+}
+
+func listsMatch(list, item *listData) bool {
+	return list.ordered == item.ordered && list.delimiter == item.delimiter && list.bulletChar == item.bulletChar
+}
+
+func atoi(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		n = n*10 + int(s[i]-'0')
+	}
+	return n
+}
+
+// incorporateLine 将一行输入合并进块树
+func (p *blockParser) incorporateLine(line string) {
+	container := p.doc
+	p.oldtip = p.tip
+	p.offset = 0
+	p.column = 0
+	p.blank = false
+	p.partiallyConsumedTab = false
+	p.lineNumber++
+
+	if strings.IndexByte(line, 0) >= 0 {
+		line = strings.ReplaceAll(line, "\x00", "�")
+	}
+	p.line = line
+
+	allMatched := true
+	for {
+		last := container.lastChild()
+		if last == nil || !last.open {
+			break
+		}
+		container = last
+		p.findNextNonspace()
+		switch p.continueBlock(container) {
+		case 1:
+			allMatched = false
+		case 2:
+			return
+		}
+		if !allMatched {
+			container = container.parent
+			break
+		}
+	}
+
+	p.allClosed = container == p.oldtip
+	p.lastMatchedContainer = container
+
+	matchedLeaf := container.kind != blockParagraph && container.acceptsLines()
+	for !matchedLeaf {
+		p.findNextNonspace()
+		matched := false
+		for _, start := range blockStarts {
+			res := start(p, container)
+			if res == 0 {
+				continue
+			}
+			container = p.tip
+			matched = true
+			matchedLeaf = res == 2
+			break
+		}
+		if !matched {
+			p.advanceNextNonspace()
+			break
+		}
+	}
+
+	if !p.allClosed && !p.blank && p.tip.kind == blockParagraph {
+		// 惰性段落延续
+		p.addLine()
+	} else {
+		p.closeUnmatchedBlocks()
+		if container.acceptsLines() {
+			p.addLine()
+			if container.kind == blockHTML && container.htmlType >= 1 && container.htmlType <= 5 &&
+				reHTMLBlockClose[container.htmlType].MatchString(p.line[p.offset:]) {
+				p.lastLineLength = len(line)
+				p.finalize(container, p.lineNumber)
+			}
+		} else if container.kind == blockTable {
+			// 分隔行在开启表格时已被消费，之后的非空行都是表格的数据行
+			if p.offset < len(line) && !p.blank {
+				p.addLine()
+			}
+		} else if p.offset < len(line) && !p.blank {
+			p.addChild(blockParagraph, p.offset)
+			p.advanceNextNonspace()
+			p.addLine()
+		}
+	}
+	p.lastLineLength = len(line)
+}
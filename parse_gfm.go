@@ -0,0 +1,366 @@
+package mdast
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	reTableDelimiterCell     = regexp.MustCompile(`^:?-+:?$`)
+	reFootnoteDefinition     = regexp.MustCompile(`^\[\^([^\[\]\s]+)\]:`)
+	reFootnoteReference      = regexp.MustCompile(`^\[\^([^\[\]\s]+)\]`)
+	reTaskListItem           = regexp.MustCompile(`^\[([ xX])\][ \t\n]+`)
+	reMainGFM                = regexp.MustCompile("^[^\n`\\[\\]\\\\!<&*_~]+")
+	reAutolinkTrailingEntity = regexp.MustCompile(`&[A-Za-z0-9]+;$`)
+)
+
+// startTable 在段落之后遇到表格分隔行时，将段落的最后一行作为表头开启 GFM 表格
+func startTable(p *blockParser, container *block) int {
+	if !p.gfm || p.indented || container.kind != blockParagraph {
+		return 0
+	}
+	align, ok := parseTableDelimiterRow(p.line[p.nextNonspace:])
+	if !ok {
+		return 0
+	}
+	p.closeUnmatchedBlocks()
+	content := p.extractDefinitions(container, container.content.String())
+	container.content.Reset()
+	container.content.WriteString(content)
+	if content == "" {
+		return 0
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	header := splitTableRow(lines[len(lines)-1])
+	if len(header) != len(align) {
+		return 0
+	}
+	headerStart := container.sourceMap().point(len(content) - len(lines[len(lines)-1]) - 1)
+
+	table := container
+	if len(lines) > 1 {
+		// 表头之前的行仍然属于原段落
+		rest := strings.Join(lines[:len(lines)-1], "\n")
+		end := container.sourceMap().point(len(rest))
+		container.content.Reset()
+		container.content.WriteString(rest + "\n")
+		p.finalize(container, end.Line)
+		container.endColumn = end.Column - 1
+		table = p.addChild(blockTable, headerStart.Column-1)
+		table.startLine = headerStart.Line
+	} else {
+		container.content.Reset()
+		container.kind = blockTable
+		container.segments = nil
+		container.contentOffset = 0
+	}
+	table.align = align
+	table.rows = [][]tableCell{header}
+	table.rowPositions = []Position{linePosition(headerStart, len(lines[len(lines)-1]))}
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return 2
+}
+
+// linePosition 返回从 start 开始、长度为 n 字节的单行位置
+func linePosition(start Point, n int) Position {
+	end := start
+	end.Column += n
+	end.Offset += n
+	return Position{Start: start, End: end}
+}
+
+// tableCell 是表格行中的一个原始单元格，offset 为其内容在行文本中的字节偏移
+type tableCell struct {
+	text   string
+	offset int
+}
+
+// parseTableDelimiterRow 解析表格分隔行，返回各列的对齐方式
+func parseTableDelimiterRow(line string) ([]AlignType, bool) {
+	cells := splitTableRow(line)
+	if len(cells) == 0 {
+		return nil, false
+	}
+	align := make([]AlignType, len(cells))
+	for i, cell := range cells {
+		if !reTableDelimiterCell.MatchString(cell.text) {
+			return nil, false
+		}
+		left, right := cell.text[0] == ':', cell.text[len(cell.text)-1] == ':'
+		switch {
+		case left && right:
+			align[i] = AlignCenter
+		case left:
+			align[i] = AlignLeft
+		case right:
+			align[i] = AlignRight
+		default:
+			align[i] = AlignNone
+		}
+	}
+	return align, true
+}
+
+// splitTableRow 按未转义的竖线拆分表格行，首尾竖线可省略
+func splitTableRow(line string) []tableCell {
+	begin := len(line) - len(strings.TrimLeft(line, " \t"))
+	end := len(strings.TrimRight(line, " \t"))
+	if begin < end && line[begin] == '|' {
+		begin++
+	}
+	if begin >= end {
+		return nil
+	}
+	var cells []tableCell
+	addCell := func(from, to int) {
+		text := strings.TrimLeft(line[from:to], " \t")
+		from = to - len(text)
+		cells = append(cells, tableCell{text: strings.TrimRight(text, " \t"), offset: from})
+	}
+	cellStart := begin
+	for i := begin; i < end; i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			addCell(cellStart, i)
+			cellStart = i + 1
+		}
+	}
+	// 行尾的竖线只是边框，不会产生额外的空单元格
+	if cellStart < end || line[end-1] != '|' {
+		addCell(cellStart, end)
+	}
+	return cells
+}
+
+// startFootnoteDefinition 开启 GFM 脚注定义 [^label]: ...
+func startFootnoteDefinition(p *blockParser, _ *block) int {
+	if !p.gfm || p.indented {
+		return 0
+	}
+	m := reFootnoteDefinition.FindStringSubmatch(p.line[p.nextNonspace:])
+	if m == nil {
+		return 0
+	}
+	p.closeUnmatchedBlocks()
+	b := p.addChild(blockFootnote, p.nextNonspace)
+	b.label = m[1]
+	p.inline.footnotes[normalizeLabel(m[1])] = true
+	p.advanceNextNonspace()
+	p.advanceOffset(len(m[0]), false)
+	return 1
+}
+
+// convertTable 将 GFM 表格块转换为 NodeTable，单元格数量保持源文本中的原样
+func (c *converter) convertTable(b *block) *Node {
+	n := NewNode(NodeTable)
+	n.SetData(NDK_Align, b.align)
+	for i, cells := range b.rows {
+		rowPos := b.rowPositions[i]
+		rowStart := rowPos.Start
+		at := func(offset int) Point {
+			return Point{Line: rowStart.Line, Column: rowStart.Column + offset, Offset: rowStart.Offset + offset}
+		}
+		row := NewNode(NodeTableRow)
+		for _, cell := range cells {
+			cellNode := NewNode(NodeTableCell)
+			cellNode.Position = &Position{Start: at(cell.offset), End: at(cell.offset + len(cell.text))}
+			sm := sourceMap{segments: []lineSegment{{start: 0, point: cellNode.Position.Start}}}
+			for _, child := range c.inline.parse(cell.text, sm) {
+				unescapeTablePipes(child.(*Node))
+				cellNode.AddPhrasingChild(child)
+			}
+			row.AddTableChild(cellNode)
+		}
+		row.Position = &rowPos
+		n.AddTableChild(row)
+	}
+	return n
+}
+
+// unescapeTablePipes 将单元格代码片段中的 \| 还原为竖线，普通文本中的 \| 已按反斜杠转义处理
+func unescapeTablePipes(n *Node) {
+	if n.Type == NodeInlineCode {
+		n.Value = strings.ReplaceAll(n.Value, "\\|", "|")
+	}
+	for _, child := range n.PhrasingChildren {
+		unescapeTablePipes(child.(*Node))
+	}
+}
+
+// taskListMarker 识别列表项首段开头的任务标记 [ ] 或 [x]，返回是否勾选以及去除标记后的内容
+func taskListMarker(content string) (checked bool, rest string, ok bool) {
+	m := reTaskListItem.FindStringSubmatch(content)
+	if m == nil || isBlankLine(content[len(m[0]):]) {
+		return false, content, false
+	}
+	return m[1] != " ", content[len(m[0]):], true
+}
+
+// parseFootnoteReference 解析已定义脚注的引用 [^label]
+func (p *inlineParser) parseFootnoteReference(block *inode) bool {
+	m := reFootnoteReference.FindStringSubmatch(p.subject[p.pos:])
+	if m == nil {
+		return false
+	}
+	identifier := normalizeLabel(m[1])
+	if !p.footnotes[identifier] {
+		return false
+	}
+	p.pos += len(m[0])
+	ref := newInode(NodeFootnoteReference, "")
+	ref.node.SetData(NDK_Identifier, identifier)
+	ref.node.SetData(NDK_Label, m[1])
+	block.appendChild(ref)
+	return true
+}
+
+// autolinkLiterals 在文本中识别 GFM 扩展自动链接（www.、http(s):// 和邮箱），链接内部的文本不处理
+func autolinkLiterals(children []PhrasingContent) []PhrasingContent {
+	result := make([]PhrasingContent, 0, len(children))
+	for _, child := range children {
+		n := child.(*Node)
+		switch n.Type {
+		case NodeText:
+			result = append(result, splitAutolinks(n)...)
+			continue
+		case NodeLink, NodeLinkReference:
+		default:
+			linked := autolinkLiterals(n.PhrasingChildren)
+			n.PhrasingChildren = []PhrasingContent{}
+			for _, c := range linked {
+				n.AddPhrasingChild(c)
+			}
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+// splitAutolinks 将文本节点按其中的自动链接拆分
+func splitAutolinks(text *Node) []PhrasingContent {
+	s := text.Value
+	var (
+		result   []PhrasingContent
+		consumed int
+	)
+	newText := func(value string) *Node {
+		n := NewNode(NodeText)
+		n.Value = value
+		return n
+	}
+	emit := func(start, end int, url string) {
+		if start > consumed {
+			result = append(result, newText(s[consumed:start]))
+		}
+		link := NewNode(NodeLink)
+		link.SetData(NDK_URL, url)
+		link.AddPhrasingChild(newText(s[start:end]))
+		result = append(result, link)
+		consumed = end
+	}
+
+	for i := 0; i < len(s); i++ {
+		atBoundary := i == 0 || strings.IndexByte(" \t\n*_~(", s[i-1]) >= 0
+		switch {
+		case atBoundary && strings.HasPrefix(s[i:], "www."):
+			if end := autolinkEnd(s, i, i, false); end > i {
+				emit(i, end, "http://"+s[i:end])
+				i = end - 1
+			}
+		case atBoundary && (strings.HasPrefix(s[i:], "http://") || strings.HasPrefix(s[i:], "https://")):
+			domainStart := i + strings.Index(s[i:], "://") + 3
+			if end := autolinkEnd(s, i, domainStart, true); end > domainStart {
+				emit(i, end, s[i:end])
+				i = end - 1
+			}
+		case s[i] == '@':
+			start := i
+			for start > consumed && isEmailLocalChar(s[start-1]) {
+				start--
+			}
+			if start == i {
+				continue
+			}
+			if end := emailDomainEnd(s, i+1); end > 0 {
+				emit(start, end, "mailto:"+s[start:end])
+				i = end - 1
+			}
+		}
+	}
+	if consumed == 0 {
+		return []PhrasingContent{text}
+	}
+	if consumed < len(s) {
+		result = append(result, newText(s[consumed:]))
+	}
+	return result
+}
+
+// autolinkEnd 计算从 start 开始的扩展自动链接的结束位置，返回 -1 表示域名不合法
+func autolinkEnd(s string, start, domainStart int, allowShort bool) int {
+	end := domainStart
+	for end < len(s) && (isAlnum(s[end]) || s[end] == '-' || s[end] == '_' || s[end] == '.' || s[end] >= 0x80) {
+		end++
+	}
+	domain := strings.TrimRight(s[domainStart:end], ".")
+	segments := strings.Split(domain, ".")
+	if domain == "" || (!allowShort && len(segments) < 2) {
+		return -1
+	}
+	for i, seg := range segments {
+		if seg == "" || (i >= len(segments)-2 && strings.IndexByte(seg, '_') >= 0) {
+			return -1
+		}
+	}
+
+	for end < len(s) && s[end] != ' ' && s[end] != '\t' && s[end] != '\n' && s[end] != '<' {
+		end++
+	}
+	// 去除链接末尾的标点、不配对的右括号以及形似实体引用的结尾
+	for end > start {
+		link := s[start:end]
+		last := link[len(link)-1]
+		switch {
+		case strings.IndexByte("?!.,:*_~'\"", last) >= 0:
+			end--
+		case last == ')' && strings.Count(link, ")") > strings.Count(link, "("):
+			end--
+		case last == ';':
+			if m := reAutolinkTrailingEntity.FindString(link); m != "" {
+				end -= len(m)
+				continue
+			}
+			return end
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// emailDomainEnd 计算邮箱自动链接域名部分的结束位置，返回 -1 表示不合法
+func emailDomainEnd(s string, start int) int {
+	end := start
+	for end < len(s) && (isAlnum(s[end]) || s[end] == '-' || s[end] == '_' || s[end] == '.') {
+		end++
+	}
+	end = start + len(strings.TrimRight(s[start:end], "."))
+	domain := s[start:end]
+	if !strings.Contains(domain, ".") || strings.Contains(domain, "..") || domain[0] == '.' {
+		return -1
+	}
+	if last := domain[len(domain)-1]; last == '-' || last == '_' {
+		return -1
+	}
+	return end
+}
+
+func isEmailLocalChar(c byte) bool {
+	return isAlnum(c) || c == '.' || c == '-' || c == '_' || c == '+'
+}
+
+func isAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
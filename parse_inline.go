@@ -0,0 +1,868 @@
+package mdast
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	reLinkTitle             = regexp.MustCompile(`^(?:"(?:\\[\s\S]|[^\\"\x00])*"|'(?:\\[\s\S]|[^\\'\x00])*'|\((?:\\[\s\S]|[^\\()\x00])*\))`)
+	reLinkDestinationBraces = regexp.MustCompile(`^<(?:[^<>\n\\\x00]|\\.)*>`)
+	reLinkLabel             = regexp.MustCompile(`^\[(?:[^\\\[\]]|\\.){0,1000}\]`)
+	reTicks                 = regexp.MustCompile("`+")
+	reTicksHere             = regexp.MustCompile("^`+")
+	reEmailAutolink         = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	reAutolink              = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9.+-]{1,31}:[^<>\x00-\x20]*>`)
+	reSpnl                  = regexp.MustCompile(`^ *(?:\n *)?`)
+	reInitialSpace          = regexp.MustCompile(`^ *`)
+	reSpaceAtEndOfLine      = regexp.MustCompile(`^ *(?:\n|$)`)
+	reMain                  = regexp.MustCompile("^[^\n`\\[\\]\\\\!<&*_]+")
+)
+
+// inode 是行内解析阶段使用的双向链表树节点，解析完成后会被转换为 PhrasingContent
+type inode struct {
+	node   *Node
+	parent *inode
+	first  *inode
+	last   *inode
+	prev   *inode
+	next   *inode
+
+	// start 与 end 是节点在行内内容中的字节范围
+	start, end int
+	hasPos     bool
+	// reference 表示文本来自字符引用，行尾不会被当作空白裁掉
+	reference bool
+}
+
+func (n *inode) setPos(start, end int) {
+	n.start, n.end, n.hasPos = start, end, true
+}
+
+func newInode(t NodeType, value string) *inode {
+	return &inode{node: &Node{Type: t, Value: value, Data: make(DataTable)}}
+}
+
+func (n *inode) appendChild(child *inode) {
+	child.unlink()
+	child.parent = n
+	if n.last != nil {
+		n.last.next = child
+		child.prev = n.last
+		n.last = child
+	} else {
+		n.first = child
+		n.last = child
+	}
+}
+
+func (n *inode) insertAfter(sibling *inode) {
+	sibling.unlink()
+	sibling.next = n.next
+	if sibling.next != nil {
+		sibling.next.prev = sibling
+	}
+	sibling.prev = n
+	n.next = sibling
+	sibling.parent = n.parent
+	if sibling.next == nil && sibling.parent != nil {
+		sibling.parent.last = sibling
+	}
+}
+
+func (n *inode) unlink() {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else if n.parent != nil {
+		n.parent.first = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else if n.parent != nil {
+		n.parent.last = n.prev
+	}
+	n.parent, n.prev, n.next = nil, nil, nil
+}
+
+// delimiter 是强调等定界符栈中的一项
+type delimiter struct {
+	char       byte
+	numDelims  int
+	origDelims int
+	node       *inode
+	previous   *delimiter
+	next       *delimiter
+	canOpen    bool
+	canClose   bool
+}
+
+// bracket 是链接/图片开括号栈中的一项
+type bracket struct {
+	node              *inode
+	previous          *bracket
+	previousDelimiter *delimiter
+	index             int
+	image             bool
+	active            bool
+	bracketAfter      bool
+}
+
+// inlineParser 实现 CommonMark 的行内解析
+type inlineParser struct {
+	subject    string
+	pos        int
+	delimiters *delimiter
+	brackets   *bracket
+
+	// refs 以规范化后的标签为键保存链接定义
+	refs map[string]*Node
+
+	// gfm 开启后识别删除线、脚注引用和扩展自动链接，footnotes 记录已定义的脚注标签
+	gfm       bool
+	footnotes map[string]bool
+
+	// srcMap 将当前内容中的位置映射回源文本
+	srcMap sourceMap
+}
+
+func newInlineParser(gfm bool) *inlineParser {
+	return &inlineParser{refs: make(map[string]*Node), gfm: gfm, footnotes: make(map[string]bool)}
+}
+
+func (p *inlineParser) peek() int {
+	if p.pos < len(p.subject) {
+		return int(p.subject[p.pos])
+	}
+	return -1
+}
+
+func (p *inlineParser) match(re *regexp.Regexp) (string, bool) {
+	loc := re.FindStringIndex(p.subject[p.pos:])
+	if loc == nil {
+		return "", false
+	}
+	m := p.subject[p.pos+loc[0] : p.pos+loc[1]]
+	p.pos += loc[1]
+	return m, true
+}
+
+func (p *inlineParser) spnl() bool {
+	p.match(reSpnl)
+	return true
+}
+
+// parse 解析一段行内内容，返回短语子节点，sm 用于计算各节点的源位置
+func (p *inlineParser) parse(content string, sm sourceMap) []PhrasingContent {
+	root := newInode(NodeParagraph, "")
+	p.subject = strings.TrimLeft(content, " \t\n\r\v\f")
+	sm.shift += len(content) - len(p.subject)
+	p.subject = trimMarkdownSpace(p.subject)
+	p.srcMap = sm
+	p.pos = 0
+	p.delimiters = nil
+	p.brackets = nil
+	for p.parseInline(root) {
+	}
+	p.processEmphasis(nil)
+	if p.gfm {
+		return autolinkLiterals(p.inodeChildren(root))
+	}
+	return p.inodeChildren(root)
+}
+
+func (p *inlineParser) parseInline(block *inode) bool {
+	c := p.peek()
+	if c == -1 {
+		return false
+	}
+	start, last := p.pos, block.last
+	var res bool
+	switch c {
+	case '\n':
+		res = p.parseNewline(block)
+	case '\\':
+		res = p.parseBackslash(block)
+	case '`':
+		res = p.parseBackticks(block)
+	case '*', '_':
+		res = p.handleDelim(byte(c), block)
+	case '~':
+		if p.gfm {
+			res = p.handleDelim(byte(c), block)
+		} else {
+			res = p.parseString(block)
+		}
+	case '[':
+		res = (p.gfm && p.parseFootnoteReference(block)) || p.parseOpenBracket(block)
+	case '!':
+		res = p.parseBang(block)
+	case ']':
+		res = p.parseCloseBracket(block)
+	case '<':
+		res = p.parseAutolink(block) || p.parseHTMLTag(block)
+	case '&':
+		res = p.parseEntity(block)
+	default:
+		res = p.parseString(block)
+	}
+	if !res {
+		_, size := utf8.DecodeRuneInString(p.subject[p.pos:])
+		block.appendChild(newInode(NodeText, p.subject[p.pos:p.pos+size]))
+		p.pos += size
+	}
+	// 本次新增且未设置位置的节点覆盖刚刚消费的输入
+	added := block.first
+	if last != nil {
+		added = last.next
+	}
+	for ; added != nil; added = added.next {
+		if !added.hasPos {
+			added.setPos(start, p.pos)
+		}
+	}
+	return true
+}
+
+func (p *inlineParser) parseNewline(block *inode) bool {
+	p.pos++
+	last := block.last
+	if last != nil && last.node.Type == NodeText && !last.reference && strings.HasSuffix(last.node.Value, " ") {
+		hardbreak := strings.HasSuffix(last.node.Value, "  ")
+		trimmed := strings.TrimRight(last.node.Value, " ")
+		spaces := len(last.node.Value) - len(trimmed)
+		last.node.Value = trimmed
+		if last.hasPos {
+			last.end -= spaces
+		}
+		if hardbreak {
+			br := newInode(NodeBreak, "")
+			br.setPos(p.pos-1-spaces, p.pos)
+			block.appendChild(br)
+		} else {
+			block.appendChild(newInode(NodeText, "\n"))
+		}
+	} else {
+		block.appendChild(newInode(NodeText, "\n"))
+	}
+	p.match(reInitialSpace)
+	return true
+}
+
+func (p *inlineParser) parseBackslash(block *inode) bool {
+	p.pos++
+	if p.peek() == '\n' {
+		p.pos++
+		block.appendChild(newInode(NodeBreak, ""))
+	} else if p.pos < len(p.subject) && isEscapable(p.subject[p.pos]) {
+		block.appendChild(newInode(NodeText, p.subject[p.pos:p.pos+1]))
+		p.pos++
+	} else {
+		block.appendChild(newInode(NodeText, "\\"))
+	}
+	return true
+}
+
+func (p *inlineParser) parseBackticks(block *inode) bool {
+	ticks, ok := p.match(reTicksHere)
+	if !ok {
+		return false
+	}
+	afterOpenTicks := p.pos
+	for {
+		m, ok := p.match(reTicks)
+		if !ok {
+			break
+		}
+		if m != ticks {
+			continue
+		}
+		contents := strings.ReplaceAll(p.subject[afterOpenTicks:p.pos-len(ticks)], "\n", " ")
+		if len(contents) > 0 && contents[0] == ' ' && contents[len(contents)-1] == ' ' &&
+			strings.Trim(contents, " ") != "" {
+			contents = contents[1 : len(contents)-1]
+		}
+		block.appendChild(newInode(NodeInlineCode, contents))
+		return true
+	}
+	p.pos = afterOpenTicks
+	block.appendChild(newInode(NodeText, ticks))
+	return true
+}
+
+// scanDelims 扫描定界符串，判断其能否作为开/闭定界符
+func (p *inlineParser) scanDelims(c byte) (numDelims int, canOpen, canClose bool) {
+	start := p.pos
+	for p.pos < len(p.subject) && p.subject[p.pos] == c {
+		numDelims++
+		p.pos++
+	}
+	if numDelims == 0 {
+		return 0, false, false
+	}
+
+	before, after := '\n', '\n'
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.subject[:start])
+	}
+	if p.pos < len(p.subject) {
+		after, _ = utf8.DecodeRuneInString(p.subject[p.pos:])
+	}
+	p.pos = start
+
+	afterIsWhitespace := isUnicodeWhitespace(after)
+	afterIsPunctuation := isUnicodePunctuation(after)
+	beforeIsWhitespace := isUnicodeWhitespace(before)
+	beforeIsPunctuation := isUnicodePunctuation(before)
+
+	leftFlanking := !afterIsWhitespace && (!afterIsPunctuation || beforeIsWhitespace || beforeIsPunctuation)
+	rightFlanking := !beforeIsWhitespace && (!beforeIsPunctuation || afterIsWhitespace || afterIsPunctuation)
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || beforeIsPunctuation)
+		canClose = rightFlanking && (!leftFlanking || afterIsPunctuation)
+	} else {
+		canOpen = leftFlanking
+		canClose = rightFlanking
+	}
+	return numDelims, canOpen, canClose
+}
+
+func (p *inlineParser) handleDelim(c byte, block *inode) bool {
+	numDelims, canOpen, canClose := p.scanDelims(c)
+	if numDelims == 0 {
+		return false
+	}
+	start := p.pos
+	p.pos += numDelims
+	node := newInode(NodeText, p.subject[start:p.pos])
+	block.appendChild(node)
+
+	// GFM 删除线只接受一到两个波浪线
+	if (canOpen || canClose) && (c != '~' || numDelims <= 2) {
+		p.delimiters = &delimiter{
+			char:       c,
+			numDelims:  numDelims,
+			origDelims: numDelims,
+			node:       node,
+			previous:   p.delimiters,
+			canOpen:    canOpen,
+			canClose:   canClose,
+		}
+		if p.delimiters.previous != nil {
+			p.delimiters.previous.next = p.delimiters
+		}
+	}
+	return true
+}
+
+func (p *inlineParser) removeDelimiter(d *delimiter) {
+	if d.previous != nil {
+		d.previous.next = d.next
+	}
+	if d.next != nil {
+		d.next.previous = d.previous
+	} else {
+		p.delimiters = d.previous
+	}
+}
+
+func removeDelimitersBetween(bottom, top *delimiter) {
+	if bottom.next != top {
+		bottom.next = top
+		top.previous = bottom
+	}
+}
+
+// openersBottomIndex 计算 openers_bottom 的索引，用于避免重复向前查找开定界符
+func openersBottomIndex(d *delimiter) int {
+	idx := d.origDelims % 3
+	if d.canOpen {
+		idx += 3
+	}
+	switch d.char {
+	case '*':
+		idx += 6
+	case '~':
+		idx += 12
+	}
+	return idx
+}
+
+// processEmphasis 处理定界符栈中位于 stackBottom 之上的所有强调
+func (p *inlineParser) processEmphasis(stackBottom *delimiter) {
+	var openersBottom [18]*delimiter
+	for i := range openersBottom {
+		openersBottom[i] = stackBottom
+	}
+
+	closer := p.delimiters
+	for closer != nil && closer.previous != stackBottom {
+		closer = closer.previous
+	}
+
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+
+		bottomIndex := openersBottomIndex(closer)
+		opener := closer.previous
+		openerFound := false
+		for opener != nil && opener != stackBottom && opener != openersBottom[bottomIndex] {
+			oddMatch := (closer.canOpen || opener.canClose) && closer.origDelims%3 != 0 &&
+				(opener.origDelims+closer.origDelims)%3 == 0
+			if closer.char == '~' {
+				// 删除线的开闭定界符长度必须一致
+				oddMatch = opener.numDelims != closer.numDelims
+			}
+			if opener.char == closer.char && opener.canOpen && !oddMatch {
+				openerFound = true
+				break
+			}
+			opener = opener.previous
+		}
+
+		oldCloser := closer
+		if openerFound {
+			useDelims := 1
+			if closer.numDelims >= 2 && opener.numDelims >= 2 {
+				useDelims = 2
+			}
+			if closer.char == '~' {
+				useDelims = closer.numDelims
+			}
+			openerInl, closerInl := opener.node, closer.node
+			opener.numDelims -= useDelims
+			closer.numDelims -= useDelims
+			openerInl.node.Value = openerInl.node.Value[:len(openerInl.node.Value)-useDelims]
+			closerInl.node.Value = closerInl.node.Value[:len(closerInl.node.Value)-useDelims]
+
+			emphType := NodeEmphasis
+			if closer.char == '~' {
+				emphType = NodeDelete
+			} else if useDelims == 2 {
+				emphType = NodeStrong
+			}
+			emph := newInode(emphType, "")
+			emph.setPos(openerInl.end-useDelims, closerInl.start+useDelims)
+			openerInl.end -= useDelims
+			closerInl.start += useDelims
+			for tmp := openerInl.next; tmp != nil && tmp != closerInl; {
+				next := tmp.next
+				emph.appendChild(tmp)
+				tmp = next
+			}
+			openerInl.insertAfter(emph)
+
+			removeDelimitersBetween(opener, closer)
+
+			if opener.numDelims == 0 {
+				openerInl.unlink()
+				p.removeDelimiter(opener)
+			}
+			if closer.numDelims == 0 {
+				closerInl.unlink()
+				next := closer.next
+				p.removeDelimiter(closer)
+				closer = next
+			}
+		} else {
+			closer = closer.next
+			openersBottom[bottomIndex] = oldCloser.previous
+			if !oldCloser.canOpen {
+				p.removeDelimiter(oldCloser)
+			}
+		}
+	}
+
+	for p.delimiters != nil && p.delimiters != stackBottom {
+		p.removeDelimiter(p.delimiters)
+	}
+}
+
+func (p *inlineParser) addBracket(node *inode, index int, image bool) {
+	if p.brackets != nil {
+		p.brackets.bracketAfter = true
+	}
+	p.brackets = &bracket{
+		node:              node,
+		previous:          p.brackets,
+		previousDelimiter: p.delimiters,
+		index:             index,
+		image:             image,
+		active:            true,
+	}
+}
+
+func (p *inlineParser) removeBracket() {
+	p.brackets = p.brackets.previous
+}
+
+func (p *inlineParser) parseOpenBracket(block *inode) bool {
+	start := p.pos
+	p.pos++
+	node := newInode(NodeText, "[")
+	block.appendChild(node)
+	p.addBracket(node, start, false)
+	return true
+}
+
+func (p *inlineParser) parseBang(block *inode) bool {
+	start := p.pos
+	p.pos++
+	if p.peek() == '[' {
+		p.pos++
+		node := newInode(NodeText, "![")
+		block.appendChild(node)
+		p.addBracket(node, start+1, true)
+	} else {
+		block.appendChild(newInode(NodeText, "!"))
+	}
+	return true
+}
+
+func (p *inlineParser) parseCloseBracket(block *inode) bool {
+	p.pos++
+	start := p.pos
+
+	opener := p.brackets
+	if opener == nil {
+		block.appendChild(newInode(NodeText, "]"))
+		return true
+	}
+	if !opener.active {
+		block.appendChild(newInode(NodeText, "]"))
+		p.removeBracket()
+		return true
+	}
+
+	isImage := opener.image
+	savePos := p.pos
+	var (
+		matched bool
+		node    *inode
+	)
+
+	// 行内链接 [text](dest "title")
+	if p.peek() == '(' {
+		p.pos++
+		p.spnl()
+		if dest, ok := p.parseLinkDestination(); ok {
+			p.spnl()
+			var title string
+			hasTitle := false
+			if c := p.subject[p.pos-1]; c == ' ' || c == '\t' || c == '\n' {
+				title, hasTitle = p.parseLinkTitle()
+			}
+			p.spnl()
+			if p.peek() == ')' {
+				p.pos++
+				matched = true
+				if isImage {
+					node = newInode(NodeImage, "")
+				} else {
+					node = newInode(NodeLink, "")
+				}
+				node.node.SetData(NDK_URL, dest)
+				if hasTitle {
+					node.node.SetData(NDK_Title, title)
+				}
+			}
+		}
+		if !matched {
+			p.pos = savePos
+		}
+	}
+
+	// 引用链接 [text][label]、[text][] 或 [text]
+	if !matched {
+		beforeLabel := p.pos
+		n := p.parseLinkLabel()
+		var (
+			refLabel string
+			refType  ReferenceType
+		)
+		switch {
+		case n > 2:
+			refLabel = p.subject[beforeLabel+1 : beforeLabel+n-1]
+			refType = ReferenceFull
+		case !opener.bracketAfter:
+			refLabel = p.subject[opener.index+1 : start-1]
+			refType = ReferenceShortcut
+			if n == 2 {
+				refType = ReferenceCollapsed
+			}
+		}
+		if n == 0 {
+			p.pos = savePos
+		}
+		if refType != "" {
+			if def, ok := p.refs[normalizeLabel(refLabel)]; ok {
+				matched = true
+				if isImage {
+					node = newInode(NodeImageReference, "")
+				} else {
+					node = newInode(NodeLinkReference, "")
+				}
+				node.node.SetData(NDK_Identifier, def.Data[NDK_Identifier])
+				node.node.SetData(NDK_Label, refLabel)
+				node.node.SetData(NDK_ReferenceType, refType)
+			}
+		}
+	}
+
+	if !matched {
+		p.removeBracket()
+		p.pos = start
+		block.appendChild(newInode(NodeText, "]"))
+		return true
+	}
+
+	for tmp := opener.node.next; tmp != nil; {
+		next := tmp.next
+		node.appendChild(tmp)
+		tmp = next
+	}
+	node.setPos(opener.node.start, p.pos)
+	block.appendChild(node)
+	p.processEmphasis(opener.previousDelimiter)
+	p.removeBracket()
+	opener.node.unlink()
+
+	// 链接内部不能再包含链接，因此需要让更早的链接开括号失效
+	if !isImage {
+		for b := p.brackets; b != nil; b = b.previous {
+			if !b.image {
+				b.active = false
+			}
+		}
+	}
+	return true
+}
+
+// parseLinkDestination 解析链接目标，返回去除转义后的字符串
+func (p *inlineParser) parseLinkDestination() (string, bool) {
+	if m, ok := p.match(reLinkDestinationBraces); ok {
+		return unescapeString(m[1 : len(m)-1]), true
+	}
+	if p.peek() == '<' {
+		return "", false
+	}
+	savePos := p.pos
+	openParens := 0
+	c := -1
+	for p.pos < len(p.subject) {
+		c = int(p.subject[p.pos])
+		if c == '\\' && p.pos+1 < len(p.subject) && isEscapable(p.subject[p.pos+1]) {
+			p.pos += 2
+		} else if c == '(' {
+			p.pos++
+			openParens++
+		} else if c == ')' {
+			if openParens < 1 {
+				break
+			}
+			p.pos++
+			openParens--
+		} else if c == ' ' || c == '\t' || c == '\n' || c == '\v' || c == '\f' || c == '\r' || c < 0x20 || c == 0x7f {
+			break
+		} else {
+			p.pos++
+		}
+	}
+	if p.pos == savePos && c != ')' {
+		return "", false
+	}
+	if openParens != 0 {
+		return "", false
+	}
+	return unescapeString(p.subject[savePos:p.pos]), true
+}
+
+// parseLinkTitle 解析链接标题，返回去除引号和转义后的字符串
+func (p *inlineParser) parseLinkTitle() (string, bool) {
+	m, ok := p.match(reLinkTitle)
+	if !ok {
+		return "", false
+	}
+	return unescapeString(m[1 : len(m)-1]), true
+}
+
+// parseLinkLabel 解析链接标签，返回消费的字节数，0 表示不是合法标签
+func (p *inlineParser) parseLinkLabel() int {
+	m := reLinkLabel.FindString(p.subject[p.pos:])
+	if m == "" || len(m) > 1001 {
+		return 0
+	}
+	p.pos += len(m)
+	return len(m)
+}
+
+// parseReference 尝试从 s 的开头解析一个链接引用定义，返回定义节点和消费的字节数
+func (p *inlineParser) parseReference(s string) (*Node, int) {
+	p.subject = s
+	p.pos = 0
+
+	n := p.parseLinkLabel()
+	if n == 0 {
+		return nil, 0
+	}
+	rawLabel := s[1 : n-1]
+
+	if p.peek() != ':' {
+		return nil, 0
+	}
+	p.pos++
+
+	p.spnl()
+	dest, ok := p.parseLinkDestination()
+	if !ok {
+		return nil, 0
+	}
+
+	beforeTitle := p.pos
+	p.spnl()
+	var (
+		title    string
+		hasTitle bool
+	)
+	if p.pos != beforeTitle {
+		title, hasTitle = p.parseLinkTitle()
+	}
+	if !hasTitle {
+		p.pos = beforeTitle
+	}
+
+	if _, ok := p.match(reSpaceAtEndOfLine); !ok {
+		if !hasTitle {
+			return nil, 0
+		}
+		// 标题之后还有内容时，尝试丢弃标题，只保留链接目标
+		hasTitle = false
+		p.pos = beforeTitle
+		if _, ok := p.match(reSpaceAtEndOfLine); !ok {
+			return nil, 0
+		}
+	}
+
+	normalized := normalizeLabel(rawLabel)
+	if normalized == "" {
+		return nil, 0
+	}
+
+	def := NewNode(NodeDefinition)
+	def.SetData(NDK_Identifier, normalized)
+	def.SetData(NDK_Label, rawLabel)
+	def.SetData(NDK_URL, dest)
+	if hasTitle {
+		def.SetData(NDK_Title, title)
+	}
+	if _, exists := p.refs[normalized]; !exists {
+		p.refs[normalized] = def
+	}
+	return def, p.pos
+}
+
+func (p *inlineParser) parseAutolink(block *inode) bool {
+	if m, ok := p.match(reEmailAutolink); ok {
+		dest := m[1 : len(m)-1]
+		link := newInode(NodeLink, "")
+		link.node.SetData(NDK_URL, "mailto:"+dest)
+		link.appendChild(newInode(NodeText, dest))
+		block.appendChild(link)
+		return true
+	}
+	if m, ok := p.match(reAutolink); ok {
+		dest := m[1 : len(m)-1]
+		link := newInode(NodeLink, "")
+		link.node.SetData(NDK_URL, dest)
+		link.appendChild(newInode(NodeText, dest))
+		block.appendChild(link)
+		return true
+	}
+	return false
+}
+
+func (p *inlineParser) parseHTMLTag(block *inode) bool {
+	m, ok := p.match(reHTMLTag)
+	if !ok {
+		return false
+	}
+	block.appendChild(newInode(NodeHTML, m))
+	return true
+}
+
+func (p *inlineParser) parseEntity(block *inode) bool {
+	m, ok := p.match(reEntityHere)
+	if !ok {
+		return false
+	}
+	text := newInode(NodeText, decodeEntity(m))
+	text.reference = true
+	block.appendChild(text)
+	return true
+}
+
+func (p *inlineParser) parseString(block *inode) bool {
+	re := reMain
+	if p.gfm {
+		re = reMainGFM
+	}
+	m, ok := p.match(re)
+	if !ok {
+		return false
+	}
+	block.appendChild(newInode(NodeText, m))
+	return true
+}
+
+// inodeChildren 将行内链表树转换为 PhrasingContent 切片，并合并相邻的文本节点
+func (p *inlineParser) inodeChildren(parent *inode) []PhrasingContent {
+	children := []PhrasingContent{}
+	var lastText *Node
+	for c := parent.first; c != nil; c = c.next {
+		n := c.node
+		if c.hasPos {
+			n.Position = &Position{Start: p.srcMap.point(c.start), End: p.srcMap.point(c.end)}
+		}
+		if n.Type == NodeText {
+			if n.Value == "" {
+				continue
+			}
+			if lastText != nil {
+				lastText.Value += n.Value
+				if lastText.Position != nil && n.Position != nil {
+					lastText.Position.End = n.Position.End
+				}
+				continue
+			}
+			lastText = n
+		} else {
+			lastText = nil
+		}
+		n.FlowChildren = []FlowContent{}
+		n.ListChildren = []ListContent{}
+		n.TableChildren = []TableContent{}
+		n.PhrasingChildren = []PhrasingContent{}
+		switch n.Type {
+		case NodeImage, NodeImageReference:
+			n.SetData(NDK_Alt, phrasingPlainText(p.inodeChildren(c)))
+		default:
+			for _, child := range p.inodeChildren(c) {
+				n.AddPhrasingChild(child)
+			}
+		}
+		children = append(children, n)
+	}
+	return children
+}
+
+// phrasingPlainText 提取短语内容的纯文本，用于图片的替代文本
+func phrasingPlainText(children []PhrasingContent) string {
+	var sb strings.Builder
+	for _, child := range children {
+		sb.WriteString(ToString(child.(*Node), StringOptions{}))
+	}
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package mdast
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CommonMark 中可以被反斜杠转义的 ASCII 标点
+const escapableChars = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+const (
+	reEntityText = `&(?:#[xX][a-fA-F0-9]{1,6}|#[0-9]{1,7}|[A-Za-z][A-Za-z0-9]{1,31});`
+
+	reTagName        = `[A-Za-z][A-Za-z0-9-]*`
+	reAttributeName  = `[a-zA-Z_:][a-zA-Z0-9:._-]*`
+	reUnquotedValue  = "[^\"'=<>`\\x00-\\x20]+"
+	reAttributeValue = `(?:` + reUnquotedValue + `|'[^']*'|"[^"]*")`
+	reAttribute      = `(?:\s+` + reAttributeName + `(?:\s*=\s*` + reAttributeValue + `)?)`
+	reOpenTag        = `<` + reTagName + reAttribute + `*\s*/?>`
+	reCloseTag       = `</` + reTagName + `\s*>`
+	reHTMLComment    = `<!-->|<!--->|<!--[\s\S]*?-->`
+	reProcessing     = `<\?[\s\S]*?\?>`
+	reDeclaration    = `<![A-Za-z][^>]*>`
+	reCDATA          = `<!\[CDATA\[[\s\S]*?\]\]>`
+)
+
+var (
+	reEntityOrEscape = regexp.MustCompile(`\\[!"#$%&'()*+,\-./:;<=>?@\[\\\]^_` + "`" + `{|}~]|` + reEntityText)
+	reEntityHere     = regexp.MustCompile(`^` + reEntityText)
+	reHTMLTag        = regexp.MustCompile(`^(?:` + reOpenTag + `|` + reCloseTag + `|` + reHTMLComment + `|` +
+		reProcessing + `|` + reDeclaration + `|` + reCDATA + `)`)
+	reLabelWhitespace = regexp.MustCompile(`[ \t\r\n]+`)
+)
+
+// isEscapable 判断字符是否可以被反斜杠转义
+func isEscapable(c byte) bool {
+	return strings.IndexByte(escapableChars, c) >= 0
+}
+
+// isSpaceOrTab 判断字符是否为空格或制表符
+func isSpaceOrTab(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isUnicodeWhitespace 判断字符是否为 CommonMark 定义的 Unicode 空白
+func isUnicodeWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\f', '\r':
+		return true
+	}
+	return unicode.Is(unicode.Zs, r)
+}
+
+// isUnicodePunctuation 判断字符是否为 CommonMark 定义的 Unicode 标点（P 或 S 类）
+func isUnicodePunctuation(r rune) bool {
+	if r < utf8.RuneSelf {
+		return strings.IndexByte(escapableChars, byte(r)) >= 0
+	}
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// decodeEntity 解码单个实体或数字字符引用，无法识别时返回原文
+func decodeEntity(s string) string {
+	if len(s) < 3 || s[0] != '&' || s[len(s)-1] != ';' {
+		return s
+	}
+	if s[1] == '#' {
+		var (
+			n   uint64
+			err error
+		)
+		if s[2] == 'x' || s[2] == 'X' {
+			n, err = strconv.ParseUint(s[3:len(s)-1], 16, 32)
+		} else {
+			n, err = strconv.ParseUint(s[2:len(s)-1], 10, 32)
+		}
+		if err != nil || n == 0 || n > unicode.MaxRune || (n >= 0xD800 && n <= 0xDFFF) {
+			return "�"
+		}
+		return string(rune(n))
+	}
+	decoded := html.UnescapeString(s)
+	if decoded == s || strings.HasSuffix(decoded, s[len(s)-2:]) {
+		// html.UnescapeString 也接受缺少分号的实体前缀，此时名字的剩余部分会被原样保留
+		return s
+	}
+	return decoded
+}
+
+// unescapeString 处理字符串中的反斜杠转义和字符引用
+func unescapeString(s string) string {
+	if strings.IndexByte(s, '\\') < 0 && strings.IndexByte(s, '&') < 0 {
+		return s
+	}
+	return reEntityOrEscape.ReplaceAllStringFunc(s, func(m string) string {
+		if m[0] == '\\' {
+			return m[1:]
+		}
+		return decodeEntity(m)
+	})
+}
+
+// normalizeLabel 按 CommonMark 规则规范化链接标签：去除首尾空白、折叠内部空白并进行大小写折叠
+func normalizeLabel(label string) string {
+	label = strings.Trim(label, " \t\r\n")
+	label = reLabelWhitespace.ReplaceAllString(label, " ")
+	label = strings.ToLower(label)
+	// Go 的简单大小写映射无法把 ß 折叠为 ss，这里单独处理以对齐 Unicode 完整折叠
+	label = strings.ReplaceAll(label, "ß", "ss")
+	return strings.ToLower(strings.ToUpper(label))
+}
+
+// trimMarkdownSpace 去除 CommonMark 意义上的首尾空白
+func trimMarkdownSpace(s string) string {
+	return strings.Trim(s, " \t\n\r\v\f")
+}
+
+// isBlankLine 判断字符串是否只包含空格和制表符
+func isBlankLine(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != ' ' && s[i] != '\t' && s[i] != '\n' && s[i] != '\r' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package mdast
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// String 以 "行:列-行:列" 的形式输出位置
+func (p *Position) String() string {
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d-%d:%d", p.Start.Line, p.Start.Column, p.End.Line, p.End.Column)
+}
+
+// PositionError 为序列化错误附加出错节点的源位置
+type PositionError struct {
+	Position *Position
+	Type     NodeType
+	Err      error
+}
+
+func (e *PositionError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Position, e.Type, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// withPosition 在节点带有位置且错误尚未携带位置时包装错误，保留最内层节点的位置
+func withPosition(n *Node, err error) error {
+	if err == nil || n == nil || n.Position == nil {
+		return err
+	}
+	var pe *PositionError
+	if errors.As(err, &pe) {
+		return err
+	}
+	return &PositionError{Position: n.Position, Type: n.Type, Err: err}
+}
+
+// lineSegment 记录块内容中某一行起点 start 对应的源位置
+type lineSegment struct {
+	start int
+	point Point
+}
+
+// sourceMap 将块内容中的字节偏移映射回源文本位置，shift 是内容开头已被剥离的字节数
+type sourceMap struct {
+	segments []lineSegment
+	shift    int
+}
+
+// point 返回内容偏移 i 对应的源位置
+func (m sourceMap) point(i int) Point {
+	if len(m.segments) == 0 {
+		return Point{}
+	}
+	i += m.shift
+	k := sort.Search(len(m.segments), func(k int) bool { return m.segments[k].start > i }) - 1
+	if k < 0 {
+		k = 0
+	}
+	seg := m.segments[k]
+	d := i - seg.start
+	return Point{Line: seg.point.Line, Column: seg.point.Column + d, Offset: seg.point.Offset + d}
+}
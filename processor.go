@@ -0,0 +1,121 @@
+package mdast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Transformer 是作用于语法树的插件，可以原地修改 root
+type Transformer interface {
+	Transform(ctx context.Context, root *Node) error
+}
+
+// TransformerFunc 将函数适配为 Transformer
+type TransformerFunc func(ctx context.Context, root *Node) error
+
+// Transform 实现 Transformer 接口
+func (f TransformerFunc) Transform(ctx context.Context, root *Node) error {
+	return f(ctx, root)
+}
+
+// Renderer 将语法树渲染为文本
+type Renderer func(ctx context.Context, root *Node) (string, error)
+
+// PluginError 记录某个插件返回的错误
+type PluginError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("plugin %d (%s): %v", e.Index, e.Name, e.Err)
+}
+
+func (e *PluginError) Unwrap() error {
+	return e.Err
+}
+
+// namedTransformer 是带名称的插件
+type namedTransformer struct {
+	name string
+	Transformer
+}
+
+// Named 为插件指定在错误中显示的名称
+func Named(name string, t Transformer) Transformer {
+	return namedTransformer{name: name, Transformer: t}
+}
+
+// Processor 按顺序执行 解析 → 插件变换 → 渲染 的处理流程
+type Processor struct {
+	parseOptions []ParseOption
+	transformers []Transformer
+	renderer     Renderer
+}
+
+// NewProcessor 创建处理器，opts 用于解析阶段，默认使用 ToMarkdown 渲染
+func NewProcessor(opts ...ParseOption) *Processor {
+	return &Processor{
+		parseOptions: opts,
+		transformers: []Transformer{},
+		renderer: func(ctx context.Context, root *Node) (string, error) {
+			return root.ToMarkdown(ctx)
+		},
+	}
+}
+
+// Use 追加插件，插件按添加顺序执行
+func (p *Processor) Use(transformers ...Transformer) *Processor {
+	p.transformers = append(p.transformers, transformers...)
+	return p
+}
+
+// UseRenderer 替换最终的渲染器
+func (p *Processor) UseRenderer(r Renderer) *Processor {
+	p.renderer = r
+	return p
+}
+
+// Run 依次对 root 执行全部插件；某个插件失败不会阻止后续插件执行，
+// 所有失败以 PluginError 汇总返回，context 被取消时立即停止
+func (p *Processor) Run(ctx context.Context, root *Node) error {
+	var errs []error
+	for i, t := range p.transformers {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := t.Transform(ctx, root); err != nil {
+			errs = append(errs, &PluginError{Index: i, Name: transformerName(t), Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stringify 使用处理器的渲染器输出 root
+func (p *Processor) Stringify(ctx context.Context, root *Node) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return p.renderer(ctx, root)
+}
+
+// Process 解析 src，执行全部插件并渲染结果
+func (p *Processor) Process(ctx context.Context, src []byte) (string, error) {
+	root, err := Parse(ctx, src, p.parseOptions...)
+	if err != nil {
+		return "", err
+	}
+	if err := p.Run(ctx, root); err != nil {
+		return "", err
+	}
+	return p.Stringify(ctx, root)
+}
+
+func transformerName(t Transformer) string {
+	if named, ok := t.(namedTransformer); ok {
+		return named.name
+	}
+	return fmt.Sprintf("%T", t)
+}
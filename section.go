@@ -0,0 +1,171 @@
+package mdast
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Section 是文档中由标题开始的一节，包括标题之后、下一个同级或更高级标题之前的所有内容，
+// 其中更低级的标题各自开始一个子节。Section 引用的是树中的节点，修改节点会直接修改文档
+type Section struct {
+	// Heading 是这一节的标题，SplitSections 返回的顶层 Section 没有标题
+	Heading *Node
+	// Content 是标题之后、第一个子节之前的内容
+	Content []*Node
+	// Subsections 是按文档顺序排列的子节
+	Subsections []*Section
+	id          string
+}
+
+// SplitSections 按 root 下一级标题的级别将文档划分为嵌套的节，返回的顶层 Section 没有标题，
+// 它的 Content 是第一个标题之前的内容。嵌套在引用块等容器中的标题不划分节
+func SplitSections(root *Node) *Section {
+	ids := headingSlugs(root)
+	top := &Section{}
+	stack := []*Section{top}
+	for _, child := range root.FlowChildren {
+		n := child.(*Node)
+		if n.Type != NodeHeading {
+			current := stack[len(stack)-1]
+			current.Content = append(current.Content, n)
+			continue
+		}
+		s := &Section{Heading: n, id: ids[n]}
+		for len(stack) > 1 && stack[len(stack)-1].Depth() >= s.Depth() {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.Subsections = append(parent.Subsections, s)
+		stack = append(stack, s)
+	}
+	return top
+}
+
+// Depth 返回节标题的级别，顶层 Section 为 0，缺少级别的标题按 1 级处理
+func (s *Section) Depth() int {
+	if s.Heading == nil {
+		return 0
+	}
+	return max(s.Heading.Depth(), 1)
+}
+
+// ID 返回节标题的锚点，与 HeadingIDs 生成的 id 相同
+func (s *Section) ID() string {
+	return s.id
+}
+
+// Nodes 按文档顺序返回这一节的所有节点，包括标题和子节
+func (s *Section) Nodes() []*Node {
+	var nodes []*Node
+	if s.Heading != nil {
+		nodes = append(nodes, s.Heading)
+	}
+	nodes = append(nodes, s.Content...)
+	for _, sub := range s.Subsections {
+		nodes = append(nodes, sub.Nodes()...)
+	}
+	return nodes
+}
+
+// Find 按文档顺序查找标题文本（忽略大小写和首尾空白）或锚点与 query 相同的节，包括 s 本身，找不到时返回 nil
+func (s *Section) Find(query string) *Section {
+	if s.Heading != nil && (s.id == query || strings.EqualFold(strings.TrimSpace(ToString(s.Heading, StringOptions{})), strings.TrimSpace(query))) {
+		return s
+	}
+	for _, sub := range s.Subsections {
+		if found := sub.Find(query); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findSection 查找 query 对应的节，返回它在 root.FlowChildren 中的起始下标
+func findSection(root *Node, query string) (*Section, int, error) {
+	s := SplitSections(root).Find(query)
+	if s == nil {
+		return nil, 0, fmt.Errorf("section %q not found", query)
+	}
+	return s, slices.Index(root.FlowChildren, FlowContent(s.Heading)), nil
+}
+
+// removeRange 从 root 中删除 [i, i+count) 的子节点并清除它们的父节点指针
+func removeRange(root *Node, i, count int) {
+	for _, child := range root.FlowChildren[i : i+count] {
+		child.(*Node).parent = nil
+	}
+	root.splice(slotFlow, i, count, nil)
+}
+
+// ExtractSection 复制 query 对应的节（包括标题和子节），返回以这些节点为内容的新根节点，不修改 root
+func ExtractSection(root *Node, query string) (*Node, error) {
+	s, _, err := findSection(root, query)
+	if err != nil {
+		return nil, err
+	}
+	result := NewRoot()
+	for _, n := range s.Nodes() {
+		result.AddFlowChild(n.Clone())
+	}
+	return result, nil
+}
+
+// ReplaceSection 保留 query 对应节的标题，将其余内容（包括子节）替换为 nodes，已有父节点的节点会先从原位置移出
+func ReplaceSection(root *Node, query string, nodes ...*Node) error {
+	s, _, err := findSection(root, query)
+	if err != nil {
+		return err
+	}
+	old := s.Nodes()
+	for _, n := range nodes {
+		if slices.Contains(old, n) {
+			return fmt.Errorf("cannot replace section %q with its own %s node", query, n.Type)
+		}
+	}
+	if err := detach(root, nodes); err != nil {
+		return err
+	}
+	// 移出节点可能改变标题的下标，需要重新定位
+	i := slices.Index(root.FlowChildren, FlowContent(s.Heading))
+	removeRange(root, i+1, len(old)-1)
+	root.splice(slotFlow, i+1, 0, nodes)
+	return nil
+}
+
+// DeleteSection 删除 query 对应的节，包括标题和子节
+func DeleteSection(root *Node, query string) error {
+	s, i, err := findSection(root, query)
+	if err != nil {
+		return err
+	}
+	removeRange(root, i, len(s.Nodes()))
+	return nil
+}
+
+// MoveSection 将 query 对应的节（包括子节）移动到 before 对应的节之前，before 为空时移动到文档末尾。
+// 移动只改变节点顺序，节的层级由移动后的标题级别决定
+func MoveSection(root *Node, query, before string) error {
+	s, i, err := findSection(root, query)
+	if err != nil {
+		return err
+	}
+	nodes := s.Nodes()
+	var target *Section
+	if before != "" {
+		if target, _, err = findSection(root, before); err != nil {
+			return err
+		}
+		if slices.Contains(nodes, target.Heading) {
+			return fmt.Errorf("cannot move section %q before %q inside itself", query, before)
+		}
+	}
+
+	removeRange(root, i, len(nodes))
+	j := len(root.FlowChildren)
+	if target != nil {
+		j = slices.Index(root.FlowChildren, FlowContent(target.Heading))
+	}
+	root.splice(slotFlow, j, 0, nodes)
+	return nil
+}
@@ -0,0 +1,142 @@
+package mdast
+
+import (
+	"context"
+	"fmt"
+)
+
+// SerializeOptions 控制 Markdown 的输出风格，对应 mdast-util-to-markdown 的同名选项，零值字段使用默认值
+type SerializeOptions struct {
+	// Bullet 是无序列表的标记，可选 "-"、"*"、"+"，默认 "-"
+	Bullet string
+	// BulletOrdered 是有序列表序号后的分隔符，可选 "."、")"，默认 "."
+	BulletOrdered string
+	// BulletOther 是紧跟在另一个无序列表之后的无序列表使用的标记，必须与 Bullet 不同，
+	// 默认在 Bullet 为 "*" 时取 "-"，否则取 "*"。相邻的列表交替使用两种标记，否则会被合并为一个列表
+	BulletOther string
+	// BulletOrderedOther 是紧跟在另一个有序列表之后的有序列表使用的分隔符，必须与 BulletOrdered 不同，默认取另一个分隔符
+	BulletOrderedOther string
+	// Emphasis 是强调的标记，可选 "*"、"_"，默认 "*"
+	Emphasis string
+	// Strong 是加粗的标记，可选 "*"、"_"，默认 "*"
+	Strong string
+	// Fence 是代码块围栏的字符，可选 "`"、"~"，默认 "`"
+	Fence string
+	// Rule 是分隔线的字符，可选 "-"、"*"、"_"，默认 "-"
+	Rule string
+	// RuleRepetition 是分隔线字符的重复次数，至少为 3，默认 3
+	RuleRepetition int
+	// RuleSpaces 为 true 时分隔线字符之间用空格分隔
+	RuleSpaces bool
+	// Setext 为 true 时 1、2 级标题使用 setext 风格（下划线 = 或 -）
+	Setext bool
+	// CloseAtx 为 true 时 ATX 标题在末尾也输出 #
+	CloseAtx bool
+	// Quote 是链接标题优先使用的引号，可选 `"`、`'`，默认 `"`
+	Quote string
+	// IndentedCode 为 true 时没有语言的代码块在可行时输出为缩进代码块
+	IndentedCode bool
+	// RepeatListMarker 为 true 时有序列表所有项都使用起始序号：从 1 开始的列表输出为 "1. 1. 1."，
+	// 从 5 开始的列表输出为 "5. 5. 5."，这样重新解析后起始序号不变
+	RepeatListMarker bool
+	// TablePipeAlign 为 true 时按显示宽度（中日韩文字和 emoji 计为 2）补齐单元格，使各列的竖线对齐
+	TablePipeAlign bool
+	// TableCompact 为 true 时单元格两侧不加空格，分隔行使用最短的 "-"、":-"、"-:"、":-:"
+	TableCompact bool
+}
+
+// DefaultSerializeOptions 返回默认的输出风格
+func DefaultSerializeOptions() SerializeOptions {
+	return SerializeOptions{
+		Bullet:         "-",
+		BulletOrdered:  ".",
+		Emphasis:       "*",
+		Strong:         "*",
+		Fence:          "`",
+		Rule:           "-",
+		RuleRepetition: 3,
+		Quote:          `"`,
+	}
+}
+
+// withDefaults 用默认值填充零值字段并校验各选项
+func (o SerializeOptions) withDefaults() (SerializeOptions, error) {
+	def := DefaultSerializeOptions()
+	choose := func(value *string, fallback, name string, allowed ...string) error {
+		if *value == "" {
+			*value = fallback
+			return nil
+		}
+		for _, a := range allowed {
+			if *value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid %s %q, expected one of %q", name, *value, allowed)
+	}
+	if err := choose(&o.Bullet, def.Bullet, "bullet", "-", "*", "+"); err != nil {
+		return o, err
+	}
+	if err := choose(&o.BulletOrdered, def.BulletOrdered, "ordered bullet", ".", ")"); err != nil {
+		return o, err
+	}
+	otherBullet := "*"
+	if o.Bullet == "*" {
+		otherBullet = "-"
+	}
+	if err := choose(&o.BulletOther, otherBullet, "other bullet", "-", "*", "+"); err != nil {
+		return o, err
+	}
+	if o.BulletOther == o.Bullet {
+		return o, fmt.Errorf("invalid other bullet %q, expected it to differ from bullet", o.BulletOther)
+	}
+	otherOrdered := ")"
+	if o.BulletOrdered == ")" {
+		otherOrdered = "."
+	}
+	if err := choose(&o.BulletOrderedOther, otherOrdered, "other ordered bullet", ".", ")"); err != nil {
+		return o, err
+	}
+	if o.BulletOrderedOther == o.BulletOrdered {
+		return o, fmt.Errorf("invalid other ordered bullet %q, expected it to differ from ordered bullet", o.BulletOrderedOther)
+	}
+	if err := choose(&o.Emphasis, def.Emphasis, "emphasis marker", "*", "_"); err != nil {
+		return o, err
+	}
+	if err := choose(&o.Strong, def.Strong, "strong marker", "*", "_"); err != nil {
+		return o, err
+	}
+	if err := choose(&o.Fence, def.Fence, "fence", "`", "~"); err != nil {
+		return o, err
+	}
+	if err := choose(&o.Rule, def.Rule, "rule", "-", "*", "_"); err != nil {
+		return o, err
+	}
+	if err := choose(&o.Quote, def.Quote, "quote", `"`, `'`); err != nil {
+		return o, err
+	}
+	if o.RuleRepetition == 0 {
+		o.RuleRepetition = def.RuleRepetition
+	} else if o.RuleRepetition < 3 {
+		return o, fmt.Errorf("invalid rule repetition %d, expected 3 or more", o.RuleRepetition)
+	}
+	return o, nil
+}
+
+type serializeOptionsKey struct{}
+
+// WithSerializeOptions 返回携带输出风格的 context，ToMarkdown 及各 *ToMarkdown 函数都会读取它
+func WithSerializeOptions(ctx context.Context, opts SerializeOptions) context.Context {
+	return context.WithValue(ctx, serializeOptionsKey{}, opts)
+}
+
+// SerializeOptionsFrom 返回 context 中的输出风格，未设置时返回默认值
+func SerializeOptionsFrom(ctx context.Context) (SerializeOptions, error) {
+	opts, _ := ctx.Value(serializeOptionsKey{}).(SerializeOptions)
+	return opts.withDefaults()
+}
+
+// ToMarkdownWithOptions 使用显式指定的输出风格将节点转换为 Markdown
+func ToMarkdownWithOptions(ctx context.Context, n *Node, opts SerializeOptions) (string, error) {
+	return n.ToMarkdown(WithSerializeOptions(ctx, opts))
+}
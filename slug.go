@@ -0,0 +1,87 @@
+package mdast
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Slug 按 GitHub 的规则将标题文本转换为锚点：转为小写，去掉字母、数字、组合符号、连接符、空格和连字符以外的字符，
+// 再将空格替换为连字符，如 "Hello, World!" 得到 "hello-world"。不处理重复，需要去重时使用 Slugger
+func Slug(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			sb.WriteByte('-')
+		case r == '-' || unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.Pc):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// Slugger 为一篇文档生成不重复的锚点，重复的锚点依次加上 -1、-2 等后缀，与 GitHub 一致
+type Slugger struct {
+	occurrences map[string]int
+}
+
+// NewSlugger 创建 Slugger
+func NewSlugger() *Slugger {
+	return &Slugger{occurrences: map[string]int{}}
+}
+
+// Slug 返回 text 对应的锚点，与之前生成或保留的锚点都不相同
+func (s *Slugger) Slug(text string) string {
+	original := Slug(text)
+	slug := original
+	for {
+		if _, ok := s.occurrences[slug]; !ok {
+			break
+		}
+		s.occurrences[original]++
+		slug = original + "-" + strconv.Itoa(s.occurrences[original])
+	}
+	s.occurrences[slug] = 0
+	return slug
+}
+
+// Reserve 登记已被占用的锚点，之后生成的锚点会避开它
+func (s *Slugger) Reserve(slug string) {
+	if _, ok := s.occurrences[slug]; !ok {
+		s.occurrences[slug] = 0
+	}
+}
+
+// HeadingIDs 返回为标题生成锚点 id 的 Transformer，id 按文档顺序由标题的纯文本生成并存入 Data[NDK_ID]。
+// 已经设置了 id 的标题保持不变，生成的 id 不会与它们重复，因此重复执行结果稳定
+func HeadingIDs() Transformer {
+	return TransformerFunc(func(ctx context.Context, root *Node) error {
+		for n, id := range headingSlugs(root) {
+			n.SetData(NDK_ID, id)
+		}
+		return nil
+	})
+}
+
+// headingSlugs 返回树中每个标题的 id，已有的 id 原样返回，其余按 HeadingIDs 的规则生成，不修改树
+func headingSlugs(root *Node) map[*Node]string {
+	slugger := NewSlugger()
+	ids := map[*Node]string{}
+	var headings []*Node
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		if id, ok := n.Data.GetString(NDK_ID); ok {
+			slugger.Reserve(id)
+			ids[n] = id
+		} else {
+			headings = append(headings, n)
+		}
+		return Continue
+	}, NodeHeading)
+
+	for _, n := range headings {
+		ids[n] = slugger.Slug(ToString(n, StringOptions{}))
+	}
+	return ids
+}
@@ -0,0 +1,322 @@
+package mdast
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown 将节点转换为 Markdown 并流式写入 w，输出与 ToMarkdown 相同。
+// 容器节点（列表、列表项、引用块、脚注定义）通过行前缀栈输出，子节点的内容只处理一次，
+// 耗时与输出长度成线性关系，适合生成很大的文档
+func (n *Node) WriteMarkdown(ctx context.Context, w io.Writer) error {
+	mw := newMarkdownWriter(w)
+	err := mw.node(ctx, n)
+	if flushErr := mw.flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// linePrefix 是行前缀栈中的一层，对应一个容器节点
+type linePrefix struct {
+	// first 和 firstBlank 用于容器内容的第一行，rest 和 blank 用于之后的行，
+	// blank 系列用于内层输出为空的行
+	first, firstBlank string
+	rest, blank       string
+	started           bool
+	// marker 是无序列表项的标记，其他容器为空
+	marker string
+}
+
+// markdownWriter 在写入的每一行前加上当前所有容器的前缀。
+// 前缀在一行的第一个字节写入时才输出，此时才能确定该行是否为空行
+type markdownWriter struct {
+	w         *bufio.Writer
+	err       error
+	prefixes  []*linePrefix
+	lineStart bool
+	// listBullet 是紧挨在当前块之前的列表使用的标记，没有时为空
+	listBullet string
+}
+
+func newMarkdownWriter(w io.Writer) *markdownWriter {
+	return &markdownWriter{w: bufio.NewWriter(w), lineStart: true}
+}
+
+func (mw *markdownWriter) flush() error {
+	if mw.err != nil {
+		return mw.err
+	}
+	return mw.w.Flush()
+}
+
+func (mw *markdownWriter) raw(s string) {
+	if mw.err == nil {
+		_, mw.err = mw.w.WriteString(s)
+	}
+}
+
+// startLine 输出当前行的前缀，blank 表示该行没有内容。
+// 前缀从内向外计算：内层前缀为空且内容为空时，外层才按空行处理
+func (mw *markdownWriter) startLine(blank bool) {
+	mw.lineStart = false
+	if len(mw.prefixes) == 0 {
+		return
+	}
+	parts := make([]string, len(mw.prefixes))
+	for i := len(mw.prefixes) - 1; i >= 0; i-- {
+		p := mw.prefixes[i]
+		switch {
+		case !p.started && blank:
+			parts[i] = p.firstBlank
+		case !p.started:
+			parts[i] = p.first
+		case blank:
+			parts[i] = p.blank
+		default:
+			parts[i] = p.rest
+		}
+		p.started = true
+		blank = blank && parts[i] == ""
+	}
+	for _, part := range parts {
+		mw.raw(part)
+	}
+}
+
+// write 写入 s，在每行开头加上前缀
+func (mw *markdownWriter) write(s string) {
+	for s != "" {
+		if mw.lineStart {
+			mw.startLine(s[0] == '\n')
+		}
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			mw.raw(s)
+			return
+		}
+		mw.raw(s[:i+1])
+		mw.lineStart = true
+		s = s[i+1:]
+	}
+}
+
+func (mw *markdownWriter) push(p *linePrefix) {
+	mw.prefixes = append(mw.prefixes, p)
+}
+
+// pop 移除最内层的前缀，容器没有输出任何内容时仍要输出它的首行前缀，如空列表项 "-"
+func (mw *markdownWriter) pop() {
+	if p := mw.prefixes[len(mw.prefixes)-1]; !p.started && mw.lineStart {
+		mw.startLine(true)
+	}
+	mw.prefixes = mw.prefixes[:len(mw.prefixes)-1]
+}
+
+// node 输出单独的节点，与 ToMarkdown 的结果相同
+func (mw *markdownWriter) node(ctx context.Context, n *Node) error {
+	switch n.Type {
+	case NodeRoot:
+		for i, child := range n.FlowChildren {
+			if i == 0 || n.FlowChildren[i-1].GetType() != NodeList {
+				mw.listBullet = ""
+			}
+			trailer, err := mw.block(ctx, child.(*Node))
+			if err != nil {
+				return err
+			}
+			mw.write(trailer)
+		}
+		return nil
+	case NodeList, NodeBlockquote, NodeFootnoteDefinition:
+		trailer, err := mw.block(ctx, n)
+		mw.write(trailer)
+		return err
+	}
+	s, err := n.ToMarkdown(ctx)
+	if err != nil {
+		return err
+	}
+	mw.write(s)
+	return nil
+}
+
+// block 输出一个流式节点，不包括末尾的换行，返回单独输出该节点时末尾应有的换行
+func (mw *markdownWriter) block(ctx context.Context, n *Node) (string, error) {
+	var err error
+	switch n.Type {
+	case NodeBlockquote:
+		mw.push(&linePrefix{first: "> ", firstBlank: ">", rest: "> ", blank: ">"})
+		err = mw.children(ctx, n, true)
+		mw.pop()
+	case NodeFootnoteDefinition:
+		if _, ok := n.Data.GetString(NDK_Identifier); !ok {
+			return "", withPosition(n, fmt.Errorf("missing or invalid identifier for footnote definition"))
+		}
+		// 使用源文中的标签，脚注定义的后续行缩进 4 个空格
+		label := escapeLabel(n.Label())
+		mw.push(&linePrefix{first: "[^" + label + "]: ", firstBlank: "[^" + label + "]:", rest: "    "})
+		err = mw.children(ctx, n, true)
+		mw.pop()
+	case NodeList:
+		err = mw.list(ctx, n)
+	case NodeHTML:
+		// 流式内容中的 HTML 总是块级的，不能与下一个块连在一起
+		mw.write(n.Value)
+		return "\n\n", nil
+	case NodeThematicBreak:
+		if ctx, err = mw.ruleContext(ctx); err != nil {
+			return "", err
+		}
+		fallthrough
+	default:
+		s, err := FlowToMarkdown(ctx, n)
+		if err != nil {
+			return "", err
+		}
+		body := strings.TrimRight(s, "\n")
+		mw.write(body)
+		return s[len(body):], nil
+	}
+	if err != nil {
+		return "", withPosition(n, err)
+	}
+	return "\n\n", nil
+}
+
+// ruleContext 在分隔线与同一行的无序列表标记字符相同时换用其他字符，否则整行会被解析为一条分隔线
+func (mw *markdownWriter) ruleContext(ctx context.Context) (context.Context, error) {
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	var used string
+	for _, p := range mw.prefixes {
+		if !p.started {
+			used += p.marker
+		}
+	}
+	if !strings.Contains(used, opts.Rule) {
+		return ctx, nil
+	}
+	for _, rule := range []string{"-", "*", "_"} {
+		if !strings.Contains(used, rule) {
+			opts.Rule = rule
+			break
+		}
+	}
+	return WithSerializeOptions(ctx, opts), nil
+}
+
+// children 输出容器节点的流式子节点，子节点之间在 spread 时用空行分隔
+func (mw *markdownWriter) children(ctx context.Context, n *Node, spread bool) error {
+	for i, child := range n.FlowChildren {
+		child := child.(*Node)
+		if i > 0 {
+			// 段落与之后的段落或分隔线之间必须有空行，否则会被合并或成为 setext 标题
+			prev := n.FlowChildren[i-1].GetType()
+			if spread || (prev == NodeParagraph && (child.Type == NodeParagraph || child.Type == NodeThematicBreak)) {
+				mw.write("\n\n")
+			} else {
+				mw.write("\n")
+			}
+		}
+		if i == 0 || n.FlowChildren[i-1].GetType() != NodeList {
+			mw.listBullet = ""
+		}
+		if _, err := mw.block(ctx, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mw *markdownWriter) list(ctx context.Context, n *Node) error {
+	// ordered 是可选属性，未设置时按 start 是否存在决定列表类型
+	ordered := n.Ordered()
+	// spread is optional, thus we don't need to check it
+	spread, _ := n.Data.GetBool(NDK_Spread)
+	opts, err := SerializeOptionsFrom(ctx)
+	if err != nil {
+		return err
+	}
+	start := 1
+	if s, ok := n.Data.GetInt(NDK_Start); ok {
+		start = s
+	}
+	// 紧跟在使用相同标记的列表之后时换用另一种标记，否则两个列表会被合并
+	bullet, other := opts.Bullet, opts.BulletOther
+	if ordered {
+		bullet, other = opts.BulletOrdered, opts.BulletOrderedOther
+	}
+	if mw.listBullet == bullet {
+		bullet = other
+	}
+
+	for i, child := range n.ListChildren {
+		if child.GetType() != NodeListItem {
+			return fmt.Errorf("unexpected node type in list: %s", child.GetType())
+		}
+		if i > 0 {
+			if spread {
+				mw.write("\n\n")
+			} else {
+				mw.write("\n")
+			}
+		}
+		var prefix string
+		switch {
+		case !ordered:
+			prefix = bullet + " "
+		case opts.RepeatListMarker:
+			prefix = fmt.Sprintf("%d%s ", start, bullet)
+		default:
+			prefix = fmt.Sprintf("%d%s ", start+i, bullet)
+		}
+		if err := mw.listItem(ctx, child.(*Node), prefix, spread); err != nil {
+			return fmt.Errorf("error processing list item: %w", err)
+		}
+	}
+	mw.listBullet = bullet
+	return nil
+}
+
+// listItem 输出一个列表项，spread 未在列表项上设置时沿用列表的 spread，
+// 后续行按列表标记的宽度缩进
+func (mw *markdownWriter) listItem(ctx context.Context, n *Node, prefix string, spread bool) error {
+	if itemSpread, ok := n.Data.GetBool(NDK_Spread); ok {
+		spread = itemSpread
+	}
+	first := prefix
+	if checked, ok := n.Data.GetBool(NDK_Checked); ok {
+		if checked {
+			first += "[x] "
+		} else {
+			first += "[ ] "
+		}
+	}
+
+	marker := ""
+	if strings.ContainsAny(prefix[:1], "-*+") {
+		marker = prefix[:1]
+	}
+	mw.push(&linePrefix{first: first, firstBlank: strings.TrimRight(first, " "), rest: strings.Repeat(" ", len(prefix)), marker: marker})
+	err := mw.children(ctx, n, spread)
+	mw.pop()
+	if err != nil {
+		return fmt.Errorf("error processing list item child: %w", err)
+	}
+	return nil
+}
+
+// containerToMarkdown 通过 WriteMarkdown 输出容器节点，供返回字符串的序列化函数使用
+func containerToMarkdown(ctx context.Context, n *Node) (string, error) {
+	var sb strings.Builder
+	if err := n.WriteMarkdown(ctx, &sb); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
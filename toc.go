@@ -0,0 +1,130 @@
+package mdast
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TOCOptions 控制 GenerateTOC 生成的目录
+type TOCOptions struct {
+	// MinDepth 和 MaxDepth 是收录的标题级别范围，为 0 时分别取 1 和 6
+	MinDepth int
+	MaxDepth int
+	// Heading 是目录所在标题的文本，如 "Contents"，比较时忽略大小写和首尾空白。
+	// 设置后只收录该标题之后的标题，并将它下方直到下一个同级或更高级标题之间的内容替换为目录
+	Heading string
+	// Ordered 为 true 时生成有序列表
+	Ordered bool
+}
+
+// GenerateTOC 收录 root 下一级的标题，生成嵌套的目录列表，每一项是指向标题锚点的链接。
+// 锚点优先使用 Data[NDK_ID]，没有时按 HeadingIDs 的规则计算，但不会写回标题。
+// 没有可收录的标题时返回 nil 并且不修改 root；设置了 Heading 却找不到该标题时返回错误
+func GenerateTOC(root *Node, opts TOCOptions) (*Node, error) {
+	if opts.MinDepth == 0 {
+		opts.MinDepth = 1
+	}
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = 6
+	}
+	if opts.MinDepth < 1 || opts.MaxDepth > 6 || opts.MinDepth > opts.MaxDepth {
+		return nil, fmt.Errorf("invalid TOC depth range %d to %d, expected 1 to 6", opts.MinDepth, opts.MaxDepth)
+	}
+
+	// start 和 end 是目录标题下方需要替换的内容范围
+	start, end := 0, 0
+	if opts.Heading != "" {
+		marker := -1
+		for i, child := range root.FlowChildren {
+			n := child.(*Node)
+			if n.Type == NodeHeading && strings.EqualFold(strings.TrimSpace(ToString(n, StringOptions{})), strings.TrimSpace(opts.Heading)) {
+				marker = i
+				break
+			}
+		}
+		if marker < 0 {
+			return nil, fmt.Errorf("TOC heading %q not found", opts.Heading)
+		}
+		depth := root.FlowChildren[marker].(*Node).Depth()
+		start, end = marker+1, len(root.FlowChildren)
+		for i := start; i < end; i++ {
+			if n := root.FlowChildren[i].(*Node); n.Type == NodeHeading && n.Depth() <= depth {
+				end = i
+				break
+			}
+		}
+	}
+
+	// 要替换的内容中的标题既不收录，也不参与锚点的计算
+	kept := slices.Concat(root.FlowChildren[:start], root.FlowChildren[end:])
+	ids := headingSlugs(&Node{Type: NodeRoot, FlowChildren: kept})
+	var headings []*Node
+	for _, child := range root.FlowChildren[end:] {
+		n := child.(*Node)
+		if n.Type == NodeHeading && n.Depth() >= opts.MinDepth && n.Depth() <= opts.MaxDepth {
+			headings = append(headings, n)
+		}
+	}
+	if len(headings) == 0 {
+		return nil, nil
+	}
+
+	toc := buildTOC(headings, ids, opts.Ordered)
+	// 只有确实生成了目录才替换原有内容
+	if opts.Heading != "" {
+		removeRange(root, start, end-start)
+		root.splice(slotFlow, start, 0, []*Node{toc})
+	}
+	return toc, nil
+}
+
+// buildTOC 按标题级别生成嵌套列表，级别最高的标题在最外层，跳过的级别用空列表项补齐
+func buildTOC(headings []*Node, ids map[*Node]string, ordered bool) *Node {
+	top := headings[0].Depth()
+	for _, n := range headings {
+		top = min(top, n.Depth())
+	}
+
+	toc := NewList(ordered)
+	for _, n := range headings {
+		list := toc
+		for level := top; level < n.Depth(); level++ {
+			if len(list.ListChildren) == 0 {
+				list.AddListChild(NewListItem())
+			}
+			item := list.ListChildren[len(list.ListChildren)-1].(*Node)
+			if k := len(item.FlowChildren); k > 0 && item.FlowChildren[k-1].GetType() == NodeList {
+				list = item.FlowChildren[k-1].(*Node)
+			} else {
+				list = NewList(ordered)
+				item.AddFlowChild(list)
+			}
+		}
+		list.AddListChild(NewListItem(NewParagraph(NewLink("#"+ids[n], "", tocContent(n)...))))
+	}
+	return toc
+}
+
+// tocContent 复制标题的内容作为链接文本，去掉其中的链接（保留文字）、脚注和换行
+func tocContent(n *Node) []PhrasingContent {
+	var result []PhrasingContent
+	for _, child := range n.PhrasingChildren {
+		c := child.(*Node)
+		switch c.Type {
+		case NodeLink, NodeLinkReference:
+			result = append(result, tocContent(c)...)
+		case NodeFootnote, NodeFootnoteReference:
+		case NodeBreak:
+			result = append(result, NewText(" "))
+		default:
+			clone := c.Clone()
+			clone.PhrasingChildren = nil
+			for _, grandchild := range tocContent(c) {
+				clone.AddPhrasingChild(grandchild)
+			}
+			result = append(result, clone)
+		}
+	}
+	return result
+}
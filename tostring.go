@@ -0,0 +1,96 @@
+package mdast
+
+import "strings"
+
+// StringOptions 控制 ToString 提取的内容
+type StringOptions struct {
+	// IncludeHTML 为 true 时包含 HTML 节点的原文，默认忽略
+	IncludeHTML bool
+	// BlockSeparator 是相邻块级内容（段落、列表项、表格行等）之间的分隔符，为空时使用 "\n"
+	BlockSeparator string
+}
+
+// ToString 返回以 n 为根的子树的纯文本：文本、行内代码和代码块取其值，图片取替代文本，硬换行输出为 "\n"。
+// 块级内容之间插入 BlockSeparator，空的块不产生多余的分隔符；同一行的单元格之间用 "\t" 分隔，
+// 空单元格也保留分隔符，使各列对齐，只有所有单元格都为空的行才被省略。
+// 定义、脚注引用、分隔线和 YAML front matter 不包含文本
+func ToString(n *Node, opts StringOptions) string {
+	if opts.BlockSeparator == "" {
+		opts.BlockSeparator = "\n"
+	}
+	w := &textWriter{opts: opts}
+	w.node(n)
+	return w.sb.String()
+}
+
+// textWriter 在写入下一段非空文本时才输出待定的分隔符，避免空节点产生连续或多余的分隔符
+type textWriter struct {
+	opts    StringOptions
+	sb      strings.Builder
+	pending string
+}
+
+func (w *textWriter) text(s string) {
+	if s == "" {
+		return
+	}
+	if w.sb.Len() > 0 {
+		w.sb.WriteString(w.pending)
+	}
+	w.pending = ""
+	w.sb.WriteString(s)
+}
+
+func (w *textWriter) node(n *Node) {
+	switch n.Type {
+	case NodeText, NodeInlineCode, NodeCode:
+		w.text(n.Value)
+		return
+	case NodeHTML:
+		if w.opts.IncludeHTML {
+			w.text(n.Value)
+		}
+		return
+	case NodeImage, NodeImageReference:
+		alt, _ := n.Data.GetString(NDK_Alt)
+		w.text(alt)
+		return
+	case NodeBreak:
+		w.text("\n")
+		return
+	case NodeYaml, NodeDefinition, NodeFootnoteReference, NodeThematicBreak:
+		return
+	case NodeTableRow:
+		w.tableRow(n)
+		return
+	}
+
+	var sep string
+	switch n.Type {
+	case NodeRoot, NodeBlockquote, NodeList, NodeListItem, NodeFootnoteDefinition, NodeTable:
+		sep = w.opts.BlockSeparator
+	}
+	for _, child := range n.Children() {
+		w.node(child)
+		// 外层容器的分隔符覆盖内层的，内层容器末尾待定的分隔符不会重复输出
+		if sep != "" {
+			w.pending = sep
+		}
+	}
+}
+
+// tableRow 分别提取各单元格的文本，每个单元格边界写一个 "\t"
+func (w *textWriter) tableRow(n *Node) {
+	children := n.Children()
+	cells := make([]string, len(children))
+	empty := true
+	for i, child := range children {
+		cw := &textWriter{opts: w.opts}
+		cw.node(child)
+		cells[i] = cw.sb.String()
+		empty = empty && cells[i] == ""
+	}
+	if !empty {
+		w.text(strings.Join(cells, "\t"))
+	}
+}
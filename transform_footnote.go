@@ -0,0 +1,100 @@
+package mdast
+
+import (
+	"context"
+	"strconv"
+)
+
+// NormalizeFootnotes 返回整理脚注的 Transformer：
+// 行内脚注 NodeFootnote 被拆分为脚注引用和脚注定义，脚注按首次引用的顺序重新编号为 1、2、3……，
+// 所有脚注定义移动到 root 末尾。没有被引用的定义排在最后并继续编号，重复定义被删除，
+// 找不到定义的引用保持原样，新编号会跳过它们的标识符
+func NormalizeFootnotes() Transformer {
+	return TransformerFunc(func(ctx context.Context, root *Node) error {
+		idx := BuildIndex(root)
+		reserved := map[string]bool{}
+		for _, ref := range idx.Unresolved {
+			if ref.Type == NodeFootnoteReference {
+				reserved[nodeIdentifier(ref)] = true
+			}
+		}
+
+		numbers := map[*Node]string{}
+		var order []*Node
+		next := 0
+		assign := func(def *Node) string {
+			if id, ok := numbers[def]; ok {
+				return id
+			}
+			for {
+				next++
+				if id := strconv.Itoa(next); !reserved[id] {
+					numbers[def] = id
+					order = append(order, def)
+					return id
+				}
+			}
+		}
+
+		// collect 按文档顺序为 n 中的脚注引用编号，嵌套在定义中的引用在定义被编号后再处理
+		collect := func(n *Node) {
+			Visit(n, func(n *Node, ancestors []*Node) VisitAction {
+				switch n.Type {
+				case NodeFootnoteDefinition:
+					if len(ancestors) > 0 {
+						return SkipChildren
+					}
+				case NodeFootnoteReference:
+					if def, ok := idx.Resolve(n); ok {
+						setFootnoteIdentifier(n, assign(def))
+					}
+				case NodeFootnote:
+					def := NewNode(NodeFootnoteDefinition)
+					para := NewNode(NodeParagraph)
+					for _, child := range n.PhrasingChildren {
+						para.AddPhrasingChild(child)
+					}
+					def.AddFlowChild(para)
+					n.Type = NodeFootnoteReference
+					n.PhrasingChildren = []PhrasingContent{}
+					setFootnoteIdentifier(n, assign(def))
+				}
+				return Continue
+			}, NodeFootnoteDefinition, NodeFootnoteReference, NodeFootnote)
+		}
+
+		collected := 0
+		drain := func() {
+			for ; collected < len(order); collected++ {
+				collect(order[collected])
+			}
+		}
+		collect(root)
+		drain()
+		// 未被引用的定义按文档顺序排在最后
+		Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+			if idx.FootnoteDefinitions[nodeIdentifier(n)] == n {
+				assign(n)
+			}
+			return Continue
+		}, NodeFootnoteDefinition)
+		drain()
+
+		removeFlowChildren(root, func(n *Node) bool {
+			return n.Type == NodeFootnoteDefinition
+		})
+		for _, def := range order {
+			setFootnoteIdentifier(def, numbers[def])
+			root.AddFlowChild(def)
+		}
+		return nil
+	})
+}
+
+func setFootnoteIdentifier(n *Node, id string) {
+	if n.Data == nil {
+		n.Data = DataTable{}
+	}
+	n.SetData(NDK_Identifier, id)
+	n.SetData(NDK_Label, id)
+}
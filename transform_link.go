@@ -0,0 +1,207 @@
+package mdast
+
+import (
+	"context"
+	"strconv"
+)
+
+// linkKey 标识一个链接目标，地址和标题都相同的链接共用一个定义
+type linkKey struct {
+	url   string
+	title string
+}
+
+// LinksToReferences 返回将行内链接和图片转换为引用形式的 Transformer。
+// 相同地址和标题的链接共用一个定义，已有的定义会被复用，新的定义追加到 root 末尾。
+// 链接文本可以直接作为标签时使用 collapsed 或 shortcut 引用，否则使用编号标签的 full 引用
+func LinksToReferences() Transformer {
+	return TransformerFunc(func(ctx context.Context, root *Node) error {
+		idx := BuildIndex(root)
+		ids := map[linkKey]string{}
+		Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+			// 同一目标有多个定义时复用文档中靠前的那个
+			key := definitionKey(n)
+			if _, ok := ids[key]; !ok && idx.Definitions[nodeIdentifier(n)] == n {
+				ids[key] = nodeIdentifier(n)
+			}
+			return Continue
+		}, NodeDefinition)
+
+		next := 0
+		newID := func() string {
+			for {
+				next++
+				id := strconv.Itoa(next)
+				if _, ok := idx.Definitions[id]; !ok {
+					return id
+				}
+			}
+		}
+
+		var defs []*Node
+		Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+			if len(ancestors) == 0 {
+				return Continue
+			}
+			url, _ := n.Data.GetString(NDK_URL)
+			title, _ := n.Data.GetString(NDK_Title)
+			key := linkKey{url: url, title: title}
+			label, usable := referenceLabel(n)
+
+			id, ok := ids[key]
+			if !ok {
+				if _, taken := idx.Definitions[normalizeLabel(label)]; usable && !taken {
+					id = normalizeLabel(label)
+				} else {
+					id = newID()
+				}
+				def := NewNode(NodeDefinition)
+				def.SetData(NDK_Identifier, id)
+				if usable && id == normalizeLabel(label) {
+					def.SetData(NDK_Label, label)
+				} else {
+					def.SetData(NDK_Label, id)
+				}
+				def.SetData(NDK_URL, url)
+				if title != "" {
+					def.SetData(NDK_Title, title)
+				}
+				ids[key] = id
+				idx.Definitions[id] = def
+				defs = append(defs, def)
+			}
+
+			referenceType := ReferenceFull
+			if usable && normalizeLabel(label) == id {
+				referenceType = ReferenceCollapsed
+				if canShortcut(n, ancestors[len(ancestors)-1]) {
+					referenceType = ReferenceShortcut
+				}
+			}
+
+			if n.Data == nil {
+				n.Data = DataTable{}
+			}
+			if n.Type == NodeLink {
+				n.Type = NodeLinkReference
+			} else {
+				n.Type = NodeImageReference
+			}
+			delete(n.Data, NDK_URL)
+			delete(n.Data, NDK_Title)
+			n.SetData(NDK_Identifier, id)
+			if referenceType == ReferenceFull {
+				n.SetData(NDK_Label, id)
+			} else {
+				n.SetData(NDK_Label, label)
+			}
+			n.SetData(NDK_ReferenceType, referenceType)
+			return Continue
+		}, NodeLink, NodeImage)
+
+		for _, def := range defs {
+			root.AddFlowChild(def)
+		}
+		return nil
+	})
+}
+
+// ReferencesToLinks 返回将引用形式的链接和图片转换为行内形式的 Transformer。
+// 找不到定义的引用保持不变，被使用过的定义（及其重复定义）会从树中删除
+func ReferencesToLinks() Transformer {
+	return TransformerFunc(func(ctx context.Context, root *Node) error {
+		idx := BuildIndex(root)
+		used := map[string]bool{}
+		Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+			def, ok := idx.Resolve(n)
+			if !ok {
+				return Continue
+			}
+			used[nodeIdentifier(def)] = true
+
+			if n.Type == NodeLinkReference {
+				n.Type = NodeLink
+			} else {
+				n.Type = NodeImage
+			}
+			delete(n.Data, NDK_Identifier)
+			delete(n.Data, NDK_Label)
+			delete(n.Data, NDK_ReferenceType)
+			url, _ := def.Data.GetString(NDK_URL)
+			n.SetData(NDK_URL, url)
+			if title, _ := def.Data.GetString(NDK_Title); title != "" {
+				n.SetData(NDK_Title, title)
+			}
+			return Continue
+		}, NodeLinkReference, NodeImageReference)
+
+		removeFlowChildren(root, func(n *Node) bool {
+			return n.Type == NodeDefinition && used[nodeIdentifier(n)]
+		})
+		return nil
+	})
+}
+
+func definitionKey(def *Node) linkKey {
+	url, _ := def.Data.GetString(NDK_URL)
+	title, _ := def.Data.GetString(NDK_Title)
+	return linkKey{url: url, title: title}
+}
+
+// referenceLabel 返回可以直接作为引用标签的链接文本或图片替代文本，
+// 只有输出时不需要转义的纯文本才能保证标签与定义匹配
+func referenceLabel(n *Node) (string, bool) {
+	var label string
+	if n.Type == NodeImage {
+		label, _ = n.Data.GetString(NDK_Alt)
+	} else {
+		if len(n.PhrasingChildren) != 1 || n.PhrasingChildren[0].GetType() != NodeText {
+			return "", false
+		}
+		label = n.PhrasingChildren[0].(*Node).Value
+	}
+	if normalizeLabel(label) == "" || len(label) > 999 || escapeText(label, textContext{}) != label {
+		return "", false
+	}
+	return label, true
+}
+
+// canShortcut 判断 shortcut 引用 [text] 之后的内容是否会改变它的解析结果
+func canShortcut(n, parent *Node) bool {
+	siblings := parent.PhrasingChildren
+	for i, sibling := range siblings {
+		if sibling != PhrasingContent(n) {
+			continue
+		}
+		if i+1 == len(siblings) {
+			return true
+		}
+		next := siblings[i+1].(*Node)
+		switch next.Type {
+		case NodeLink, NodeLinkReference, NodeFootnoteReference, NodeFootnote:
+			return false
+		case NodeText:
+			return next.Value == "" || (next.Value[0] != '(' && next.Value[0] != '[' && next.Value[0] != ':')
+		}
+		return true
+	}
+	return false
+}
+
+// removeFlowChildren 删除树中所有满足 match 的流式节点
+func removeFlowChildren(root *Node, match func(n *Node) bool) {
+	Visit(root, func(n *Node, ancestors []*Node) VisitAction {
+		if len(n.FlowChildren) == 0 {
+			return Continue
+		}
+		kept := n.FlowChildren[:0]
+		for _, child := range n.FlowChildren {
+			if match(child.(*Node)) {
+				continue
+			}
+			kept = append(kept, child)
+		}
+		n.FlowChildren = kept
+		return Continue
+	})
+}
@@ -0,0 +1,218 @@
+package mdast
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Parent 返回节点的父节点，没有父节点或父节点未知时返回 nil。
+// 父节点由 Add*Child、InsertBefore 等方法维护，直接构造的节点可以通过 FixParents 补全
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// FixParents 根据子节点切片重新设置以 root 为根的树中每个节点的父节点
+func FixParents(root *Node) {
+	for _, child := range root.Children() {
+		child.parent = root
+		FixParents(child)
+	}
+}
+
+// childSlot 表示子节点所在的切片
+type childSlot int
+
+const (
+	slotFlow childSlot = iota
+	slotPhrasing
+	slotList
+	slotTable
+)
+
+// slotFor 返回该类型的父节点存放子节点的切片
+func slotFor(nt NodeType) childSlot {
+	switch nt {
+	case NodeList:
+		return slotList
+	case NodeTable, NodeTableRow:
+		return slotTable
+	case NodeParagraph, NodeHeading, NodeTableCell, NodeEmphasis, NodeStrong, NodeDelete,
+		NodeLink, NodeLinkReference, NodeFootnote:
+		return slotPhrasing
+	}
+	return slotFlow
+}
+
+// locate 返回 n 在父节点中所在的切片和下标
+func (n *Node) locate() (childSlot, int, error) {
+	p := n.parent
+	if p == nil {
+		return 0, 0, fmt.Errorf("%s node has no parent", n.Type)
+	}
+	match := func(c any) bool { return c.(*Node) == n }
+	if i := slices.IndexFunc(p.FlowChildren, func(c FlowContent) bool { return match(c) }); i >= 0 {
+		return slotFlow, i, nil
+	}
+	if i := slices.IndexFunc(p.PhrasingChildren, func(c PhrasingContent) bool { return match(c) }); i >= 0 {
+		return slotPhrasing, i, nil
+	}
+	if i := slices.IndexFunc(p.ListChildren, func(c ListContent) bool { return match(c) }); i >= 0 {
+		return slotList, i, nil
+	}
+	if i := slices.IndexFunc(p.TableChildren, func(c TableContent) bool { return match(c) }); i >= 0 {
+		return slotTable, i, nil
+	}
+	return 0, 0, fmt.Errorf("%s node is not among the children of its parent %s, call FixParents first", n.Type, p.Type)
+}
+
+// splice 删除切片 slot 中 [i, i+del) 的子节点，并在该位置插入 nodes
+func (n *Node) splice(slot childSlot, i, del int, nodes []*Node) {
+	for _, node := range nodes {
+		node.parent = n
+	}
+	switch slot {
+	case slotFlow:
+		n.FlowChildren = spliceContent(n.FlowChildren, i, del, nodes)
+	case slotPhrasing:
+		n.PhrasingChildren = spliceContent(n.PhrasingChildren, i, del, nodes)
+	case slotList:
+		n.ListChildren = spliceContent(n.ListChildren, i, del, nodes)
+	case slotTable:
+		n.TableChildren = spliceContent(n.TableChildren, i, del, nodes)
+	}
+}
+
+func spliceContent[T any](s []T, i, del int, nodes []*Node) []T {
+	items := make([]T, len(nodes))
+	for j, node := range nodes {
+		items[j] = any(node).(T)
+	}
+	return slices.Replace(s, i, i+del, items...)
+}
+
+// checkInsert 检查 nodes 能否插入到 target 下：nodes 中不能有 nil 或重复的节点，
+// 也不能把 target 或它的祖先插入到它下面
+func checkInsert(target *Node, nodes []*Node) error {
+	for i, node := range nodes {
+		if node == nil {
+			return fmt.Errorf("cannot insert nil node")
+		}
+		if slices.Contains(nodes[:i], node) {
+			return fmt.Errorf("cannot insert %s node more than once", node.Type)
+		}
+		for p := target; p != nil; p = p.parent {
+			if p == node {
+				return fmt.Errorf("cannot insert %s node into its own subtree", node.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// detach 将待插入的节点从原来的父节点中移出，使插入等同于移动；
+// target 是插入位置所在的节点，所有节点都通过检查后才开始移出
+func detach(target *Node, nodes []*Node) error {
+	if err := checkInsert(target, nodes); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node.parent == nil {
+			continue
+		}
+		if _, _, err := node.locate(); err != nil {
+			// 父节点已经不包含该节点，只需清除过期的指针
+			node.parent = nil
+			continue
+		}
+		if err := node.Remove(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insert 在 n 所在切片中 n 的位置加上 offset 处插入 nodes
+func (n *Node) insert(offset int, nodes []*Node) error {
+	if slices.Contains(nodes, n) {
+		return fmt.Errorf("cannot insert %s node next to itself", n.Type)
+	}
+	if _, _, err := n.locate(); err != nil {
+		return err
+	}
+	if err := detach(n.parent, nodes); err != nil {
+		return err
+	}
+	// 移出节点可能改变 n 的下标，需要重新定位
+	slot, i, err := n.locate()
+	if err != nil {
+		return err
+	}
+	n.parent.splice(slot, i+offset, 0, nodes)
+	return nil
+}
+
+// InsertBefore 将 nodes 作为兄弟节点插入到 n 之前，已有父节点的节点会先从原位置移出
+func (n *Node) InsertBefore(nodes ...*Node) error {
+	return n.insert(0, nodes)
+}
+
+// InsertAfter 将 nodes 作为兄弟节点插入到 n 之后，已有父节点的节点会先从原位置移出
+func (n *Node) InsertAfter(nodes ...*Node) error {
+	return n.insert(1, nodes)
+}
+
+// InsertChild 在 n 的第 index 个子节点处插入 child，child 放入 addChild 为 n 选择的切片，
+// 已有父节点的 child 会先从原位置移出
+func (n *Node) InsertChild(index int, child *Node) error {
+	if err := checkInsert(n, []*Node{child}); err != nil {
+		return err
+	}
+	slot := slotFor(n.Type)
+	count := [...]int{len(n.FlowChildren), len(n.PhrasingChildren), len(n.ListChildren), len(n.TableChildren)}[slot]
+	// child 已经在同一个切片中时，移出后子节点会少一个
+	if child.parent == n {
+		if s, _, err := child.locate(); err == nil && s == slot {
+			count--
+		}
+	}
+	if index < 0 || index > count {
+		return fmt.Errorf("index %d out of range [0, %d] for %s children", index, count, n.Type)
+	}
+	if err := detach(n, []*Node{child}); err != nil {
+		return err
+	}
+	n.splice(slot, index, 0, []*Node{child})
+	return nil
+}
+
+// Remove 将 n 从父节点中移出
+func (n *Node) Remove() error {
+	slot, i, err := n.locate()
+	if err != nil {
+		return err
+	}
+	n.parent.splice(slot, i, 1, nil)
+	n.parent = nil
+	return nil
+}
+
+// ReplaceWith 用 nodes 替换 n，nodes 为空时等同于 Remove，已有父节点的节点会先从原位置移出
+func (n *Node) ReplaceWith(nodes ...*Node) error {
+	if slices.Contains(nodes, n) {
+		return fmt.Errorf("cannot replace %s node with itself", n.Type)
+	}
+	if _, _, err := n.locate(); err != nil {
+		return err
+	}
+	if err := detach(n.parent, nodes); err != nil {
+		return err
+	}
+	slot, i, err := n.locate()
+	if err != nil {
+		return err
+	}
+	parent := n.parent
+	parent.splice(slot, i, 1, nodes)
+	n.parent = nil
+	return nil
+}
@@ -0,0 +1,269 @@
+package mdast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValidationError 描述语法树中一处不符合 mdast 内容模型的地方
+type ValidationError struct {
+	// Path 是从根到出错节点的路径，如 "root > list[1] > listItem[0]"，方括号中为节点在父节点子节点中的下标
+	Path    string
+	Node    *Node
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Node != nil && e.Node.Position != nil {
+		return fmt.Sprintf("%s (%s): %s", e.Path, e.Node.Position, e.Message)
+	}
+	return e.Path + ": " + e.Message
+}
+
+// contentCategory 是 mdast 规范中的内容分类
+type contentCategory int
+
+const (
+	categoryNone contentCategory = iota
+	categoryFlow
+	categoryPhrasing
+	categoryList
+	categoryTable
+	categoryRow
+	categoryAny
+)
+
+var categoryNames = map[contentCategory]string{
+	categoryFlow:     "flow",
+	categoryPhrasing: "phrasing",
+	categoryList:     "list",
+	categoryTable:    "table",
+	categoryRow:      "row",
+}
+
+// childCategory 返回节点允许的子节点分类，categoryNone 表示不能有子节点
+func childCategory(nt NodeType) contentCategory {
+	switch nt {
+	case NodeRoot:
+		return categoryAny
+	case NodeBlockquote, NodeListItem, NodeFootnoteDefinition:
+		return categoryFlow
+	case NodeParagraph, NodeHeading, NodeEmphasis, NodeStrong, NodeDelete, NodeLink,
+		NodeLinkReference, NodeTableCell, NodeFootnote:
+		return categoryPhrasing
+	case NodeList:
+		return categoryList
+	case NodeTable:
+		return categoryTable
+	case NodeTableRow:
+		return categoryRow
+	}
+	return categoryNone
+}
+
+// inCategory 判断节点类型是否属于某个内容分类
+func inCategory(nt NodeType, c contentCategory) bool {
+	switch c {
+	case categoryAny:
+		return nt != NodeRoot && knownNodeType(nt)
+	case categoryFlow:
+		switch nt {
+		case NodeBlockquote, NodeCode, NodeHeading, NodeHTML, NodeList, NodeThematicBreak,
+			NodeDefinition, NodeParagraph, NodeFootnoteDefinition, NodeTable, NodeYaml:
+			return true
+		}
+	case categoryPhrasing:
+		switch nt {
+		case NodeBreak, NodeEmphasis, NodeHTML, NodeImage, NodeImageReference, NodeInlineCode,
+			NodeLink, NodeLinkReference, NodeStrong, NodeText, NodeFootnoteReference, NodeDelete, NodeFootnote:
+			return true
+		}
+	case categoryList:
+		return nt == NodeListItem
+	case categoryTable:
+		return nt == NodeTableRow
+	case categoryRow:
+		return nt == NodeTableCell
+	}
+	return false
+}
+
+func knownNodeType(nt NodeType) bool {
+	switch nt {
+	case NodeRoot, NodeParagraph, NodeHeading, NodeThematicBreak, NodeBlockquote, NodeList, NodeListItem,
+		NodeHTML, NodeCode, NodeDefinition, NodeText, NodeEmphasis, NodeStrong, NodeInlineCode, NodeBreak,
+		NodeLink, NodeImage, NodeLinkReference, NodeImageReference, NodeFootnote, NodeFootnoteReference,
+		NodeFootnoteDefinition, NodeTable, NodeTableRow, NodeTableCell, NodeDelete, NodeYaml:
+		return true
+	}
+	return false
+}
+
+// Validate 按 mdast 规范（含 GFM 与 frontmatter 扩展）检查以 root 为根的树，
+// 包括子节点的内容分类和必需属性，按文档顺序返回所有问题，没有问题时返回 nil。root 为 nil 时返回一个问题
+func Validate(root *Node) []ValidationError {
+	v := &validator{}
+	if root == nil {
+		v.report(nil, "<nil>", "root is nil")
+		return v.errs
+	}
+	v.node(root, string(root.Type))
+	return v.errs
+}
+
+// childPath 返回第 i 个子节点的路径，格式与 ValidationError.Path 相同
+func childPath(path string, child *Node, i int) string {
+	return path + " > " + string(child.Type) + "[" + strconv.Itoa(i) + "]"
+}
+
+type validator struct {
+	errs []ValidationError
+}
+
+func (v *validator) report(n *Node, path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Node: n, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) node(n *Node, path string) {
+	if !knownNodeType(n.Type) {
+		v.report(n, path, "unknown node type %q", n.Type)
+		return
+	}
+	v.properties(n, path)
+
+	category := childCategory(n.Type)
+	// 子节点需要存放在与父节点类型对应的切片中（与 addChild 一致），否则序列化时会被忽略
+	expected := [...]string{"FlowChildren", "PhrasingChildren", "ListChildren", "TableChildren"}[slotFor(n.Type)]
+	for _, s := range []struct {
+		name  string
+		count int
+	}{
+		{"FlowChildren", len(n.FlowChildren)},
+		{"PhrasingChildren", len(n.PhrasingChildren)},
+		{"ListChildren", len(n.ListChildren)},
+		{"TableChildren", len(n.TableChildren)},
+	} {
+		if s.count == 0 {
+			continue
+		}
+		if category == categoryNone {
+			v.report(n, path, "%s cannot have children", n.Type)
+			break
+		}
+		if s.name != expected {
+			v.report(n, path, "children of %s must be stored in %s, found %d in %s", n.Type, expected, s.count, s.name)
+		}
+	}
+
+	for i, child := range n.Children() {
+		p := childPath(path, child, i)
+		if category != categoryNone && knownNodeType(child.Type) && !inCategory(child.Type, category) {
+			if category == categoryAny {
+				v.report(child, p, "%s cannot be a child of %s", child.Type, n.Type)
+			} else {
+				v.report(child, p, "%s is not %s content and cannot be a child of %s",
+					child.Type, categoryNames[category], n.Type)
+			}
+		}
+		v.node(child, p)
+	}
+}
+
+// properties 检查节点的必需属性和可选属性的类型
+func (v *validator) properties(n *Node, path string) {
+	requireString := func(key DataKey) {
+		if s, ok := n.Data.GetString(key); !ok || (key == NDK_Identifier && strings.TrimSpace(s) == "") {
+			v.report(n, path, "missing or invalid %s for %s", key, n.Type)
+		}
+	}
+	optionalString := func(key DataKey) {
+		if _, exists := n.Data[key]; exists {
+			if _, ok := n.Data.GetString(key); !ok {
+				v.report(n, path, "invalid %s for %s, expected a string", key, n.Type)
+			}
+		}
+	}
+	optionalLabel := func() {
+		optionalString(NDK_Label)
+		if label, ok := n.Data.GetString(NDK_Label); ok && len(label) > 999 {
+			v.report(n, path, "invalid label for %s, expected at most 999 characters", n.Type)
+		}
+	}
+	optionalBool := func(key DataKey) {
+		if _, exists := n.Data[key]; exists {
+			if _, ok := n.Data.GetBool(key); !ok {
+				v.report(n, path, "invalid %s for %s, expected a boolean", key, n.Type)
+			}
+		}
+	}
+
+	switch n.Type {
+	case NodeHeading:
+		if depth, ok := n.Data.GetInt(NDK_Depth); !ok {
+			v.report(n, path, "missing or invalid depth for heading")
+		} else if depth < 1 || depth > 6 {
+			v.report(n, path, "invalid depth %d for heading, expected 1 to 6", depth)
+		}
+	case NodeList:
+		optionalBool(NDK_Ordered)
+		optionalBool(NDK_Spread)
+		if _, exists := n.Data[NDK_Start]; exists {
+			if start, ok := n.Data.GetInt(NDK_Start); !ok || start < 0 {
+				v.report(n, path, "invalid start for list, expected a non-negative integer")
+			}
+		}
+	case NodeListItem:
+		optionalBool(NDK_Spread)
+		optionalBool(NDK_Checked)
+	case NodeCode:
+		optionalString(NDK_Lang)
+		optionalString(NDK_Meta)
+		if strings.ContainsAny(n.Lang(), " \t\n\r") {
+			v.report(n, path, "invalid lang %q for code, expected no whitespace", n.Lang())
+		}
+		if strings.ContainsAny(n.Meta(), "\n\r") {
+			v.report(n, path, "invalid meta for code, expected a single line")
+		}
+	case NodeDefinition:
+		requireString(NDK_Identifier)
+		optionalLabel()
+		requireString(NDK_URL)
+		optionalString(NDK_Title)
+	case NodeFootnoteDefinition, NodeFootnoteReference:
+		requireString(NDK_Identifier)
+		optionalLabel()
+	case NodeLink:
+		requireString(NDK_URL)
+		optionalString(NDK_Title)
+	case NodeImage:
+		requireString(NDK_URL)
+		optionalString(NDK_Title)
+		optionalString(NDK_Alt)
+	case NodeLinkReference, NodeImageReference:
+		requireString(NDK_Identifier)
+		optionalLabel()
+		if n.Type == NodeImageReference {
+			optionalString(NDK_Alt)
+		}
+		switch referenceType, _ := n.Data.GetReferenceType(NDK_ReferenceType); referenceType {
+		case ReferenceShortcut, ReferenceCollapsed, ReferenceFull:
+		default:
+			v.report(n, path, "missing or invalid referenceType for %s", n.Type)
+		}
+	case NodeTable:
+		if _, exists := n.Data[NDK_Align]; exists {
+			aligns, ok := n.Data.GetAlignTypes(NDK_Align)
+			for _, a := range aligns {
+				switch a {
+				case AlignNone, AlignLeft, AlignRight, AlignCenter:
+				default:
+					ok = false
+				}
+			}
+			if !ok {
+				v.report(n, path, "invalid align for table")
+			}
+		}
+	}
+}
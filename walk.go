@@ -0,0 +1,85 @@
+package mdast
+
+// VisitAction 控制 Walk 的遍历流程
+type VisitAction int
+
+const (
+	// Continue 继续遍历，进入当前节点的子节点
+	Continue VisitAction = iota
+	// SkipChildren 跳过当前节点的子节点，仅在 Enter 中有效
+	SkipChildren
+	// Stop 立即结束整个遍历
+	Stop
+)
+
+// VisitFunc 是遍历回调，ancestors 为从根到父节点的祖先栈，回调返回后会被复用，需要保留时请复制
+type VisitFunc func(n *Node, ancestors []*Node) VisitAction
+
+// Visitor 定义 Walk 的回调，Enter 在访问子节点前调用，Leave 在访问子节点后调用，均可为 nil
+type Visitor struct {
+	Enter VisitFunc
+	Leave VisitFunc
+	// Types 非空时只对这些类型的节点调用回调，其余节点仍会被遍历
+	Types []NodeType
+}
+
+// Walk 以深度优先顺序遍历以 n 为根的树
+func Walk(n *Node, v Visitor) {
+	if n == nil {
+		return
+	}
+	w := walker{visitor: v, ancestors: []*Node{}}
+	w.walk(n)
+}
+
+// Visit 在进入每个节点时调用 fn，types 非空时只对这些类型调用，类似 unist-util-visit
+func Visit(n *Node, fn VisitFunc, types ...NodeType) {
+	Walk(n, Visitor{Enter: fn, Types: types})
+}
+
+type walker struct {
+	visitor   Visitor
+	ancestors []*Node
+}
+
+func (w *walker) match(n *Node) bool {
+	if len(w.visitor.Types) == 0 {
+		return true
+	}
+	for _, t := range w.visitor.Types {
+		if n.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// walk 返回 false 表示遍历已被 Stop 终止
+func (w *walker) walk(n *Node) bool {
+	matched := w.match(n)
+	if matched && w.visitor.Enter != nil {
+		switch w.visitor.Enter(n, w.ancestors) {
+		case Stop:
+			return false
+		case SkipChildren:
+			return w.leave(n, matched)
+		}
+	}
+
+	// 先取出子节点快照，回调中修改子节点切片不会影响本次遍历
+	w.ancestors = append(w.ancestors, n)
+	for _, child := range n.Children() {
+		if !w.walk(child) {
+			return false
+		}
+	}
+	w.ancestors = w.ancestors[:len(w.ancestors)-1]
+	return w.leave(n, matched)
+}
+
+func (w *walker) leave(n *Node, matched bool) bool {
+	if matched && w.visitor.Leave != nil {
+		return w.visitor.Leave(n, w.ancestors) != Stop
+	}
+	return true
+}